@@ -16,9 +16,11 @@ import (
 
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -29,6 +31,9 @@ const (
 	defaultMemoryRequest      string             = "2Gi"
 	defaultCPURequest         string             = "1000m"
 	defaultServiceType        corev1.ServiceType = corev1.ServiceTypeClusterIP
+
+	defaultTerminationGracePeriodSeconds int64 = 60 * 60 * 24 * 7
+	minPreStopTimeoutSeconds             int64 = 1
 )
 
 // +kubebuilder:object:root=true
@@ -53,21 +58,212 @@ type RabbitmqClusterSpec struct {
 	// Image is the name of the RabbitMQ docker image to use for RabbitMQ nodes in the RabbitmqCluster.
 	Image string `json:"image,omitempty"`
 	// Name of the Secret resource containing access credentials to the registry for the RabbitMQ image. Required if the docker registry is private.
-	ImagePullSecret string                         `json:"imagePullSecret,omitempty"`
-	Service         RabbitmqClusterServiceSpec     `json:"service,omitempty"`
-	Persistence     RabbitmqClusterPersistenceSpec `json:"persistence,omitempty"`
-	Resources       *corev1.ResourceRequirements   `json:"resources,omitempty"`
-	Affinity        *corev1.Affinity               `json:"affinity,omitempty"`
+	ImagePullSecret string `json:"imagePullSecret,omitempty"`
+	// SetupContainerImage is the image of the setup-container of the RabbitMQ Pods, which runs the setup binary of the Cluster Operator.
+	// Defaults to the image of the Cluster Operator. Set it to a copy of the operator image that spec.imagePullSecret can pull, when the Pods pull from a private registry.
+	SetupContainerImage string                     `json:"setupContainerImage,omitempty"`
+	Service             RabbitmqClusterServiceSpec `json:"service,omitempty"`
+	// AdditionalServices are Services exposing a subset of the ports of the client Service, each with its own type and annotations.
+	// Services removed from this list are deleted.
+	AdditionalServices []RabbitmqClusterAdditionalServiceSpec `json:"additionalServices,omitempty"`
+	// PerPodService configures one Service per RabbitMQ node, so that clients outside the Kubernetes cluster can connect to a specific node.
+	PerPodService *RabbitmqClusterPerPodServiceSpec `json:"perPodService,omitempty"`
+	Persistence   RabbitmqClusterPersistenceSpec    `json:"persistence,omitempty"`
+	Resources     *corev1.ResourceRequirements      `json:"resources,omitempty"`
+	Affinity      *corev1.Affinity                  `json:"affinity,omitempty"`
+	// Placement opts in to a preferred pod anti-affinity and topology spread constraints, which are applied when Affinity is not set.
+	// Without it, the Pod template has no anti-affinity or topology spread constraints.
+	Placement *RabbitmqClusterPlacementSpec `json:"placement,omitempty"`
 	// Tolerations is the list of Toleration resources attached to each Pod in the RabbitmqCluster.
 	Tolerations []corev1.Toleration              `json:"tolerations,omitempty"`
 	Rabbitmq    RabbitmqClusterConfigurationSpec `json:"rabbitmq,omitempty"`
 	TLS         TLSSpec                          `json:"tls,omitempty"`
 	Override    RabbitmqClusterOverrideSpec      `json:"override,omitempty"`
+	// Monitoring configures Prometheus Operator resources for the RabbitmqCluster.
+	// When set, and the monitoring.coreos.com CRDs are installed, the operator creates a PodMonitor or ServiceMonitor and a PrometheusRule.
+	Monitoring *RabbitmqClusterMonitoringSpec `json:"monitoring,omitempty"`
+	// Management configures how the management UI and HTTP API are exposed outside the Kubernetes cluster.
+	Management *RabbitmqClusterManagementSpec `json:"management,omitempty"`
+	// NetworkPolicy restricts ingress traffic to the RabbitMQ Pods.
+	// When set, epmd and the Erlang distribution port only accept connections from the other RabbitMQ Pods of the RabbitmqCluster.
+	NetworkPolicy *RabbitmqClusterNetworkPolicySpec `json:"networkPolicy,omitempty"`
+	// TerminationGracePeriodSeconds is the time RabbitMQ nodes are given to stop gracefully, including the pre-stop checks. Defaults to 604800 (7 days).
+	// +kubebuilder:validation:Minimum:=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// PreStop configures the checks each RabbitMQ node runs before it is stopped.
+	// The checks are skipped when the RabbitmqCluster is deleted.
+	PreStop *RabbitmqClusterPreStopSpec `json:"preStop,omitempty"`
+	// Probes configures the readiness, liveness and startup probes of the RabbitMQ container.
+	Probes *RabbitmqClusterProbesSpec `json:"probes,omitempty"`
+	// SecurityProfile selects the security settings of the RabbitMQ Pods. Defaults to Default, which runs RabbitMQ as user and group 999.
+	// Restricted is compatible with the OpenShift restricted SCC and the Pod Security restricted profile:
+	// the user and group are assigned from the range of the namespace, the fsGroup stays 999, the setup container runs unprivileged,
+	// and every container runs as non-root, without privilege escalation or capabilities, and with the RuntimeDefault seccomp profile.
+	// On Kubernetes clusters which do not assign a user to Pods, set it with spec.override.statefulSet.
+	// +kubebuilder:validation:Enum=Default;Restricted
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+}
+
+// Settable attributes for the pre-stop hook of the RabbitMQ containers.
+type RabbitmqClusterPreStopSpec struct {
+	// Drain puts the node into maintenance mode with rabbitmq-upgrade drain before the other checks run,
+	// so that clients and quorum queue leaders move to other nodes.
+	Drain bool `json:"drain,omitempty"`
+	// AwaitQuorumPlusOne waits until every quorum queue the node hosts a replica of has at least one replica more than its quorum online.
+	// Defaults to true.
+	AwaitQuorumPlusOne *bool `json:"awaitQuorumPlusOne,omitempty"`
+	// AwaitSynchronizedMirror waits until every classic mirrored queue the node hosts has at least one synchronised mirror on another node.
+	// Defaults to true.
+	AwaitSynchronizedMirror *bool `json:"awaitSynchronizedMirror,omitempty"`
+	// TimeoutSeconds is the time each step of the pre-stop hook may take.
+	// Defaults to, and is capped at, the termination grace period divided by the number of steps, so that all steps fit within it,
+	// with a minimum of 1. When neither spec.preStop nor spec.terminationGracePeriodSeconds is set, each step may take 604800 seconds (7 days).
+	// +kubebuilder:validation:Minimum:=0
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// Settable attributes for the probes of the RabbitMQ container.
+type RabbitmqClusterProbesSpec struct {
+	// Readiness configures the readiness probe, which runs check_port_connectivity by default.
+	Readiness *RabbitmqClusterProbeSpec `json:"readiness,omitempty"`
+	// Liveness configures a liveness probe, which runs ping by default. No liveness probe is set when nil.
+	Liveness *RabbitmqClusterProbeSpec `json:"liveness,omitempty"`
+	// Startup configures the startup probe, which runs check_running by default and allows nodes 30 minutes to boot.
+	// The liveness and readiness probes only start once the startup probe succeeds, so that nodes recovering a lot of data on boot are not restarted.
+	Startup *RabbitmqClusterProbeSpec `json:"startup,omitempty"`
+}
+
+// Settable attributes for a probe of the RabbitMQ container.
+// Unset timings default to values suited to the type of the probe.
+type RabbitmqClusterProbeSpec struct {
+	// Check is the rabbitmq-diagnostics command the probe runs.
+	// +kubebuilder:validation:Enum=ping;check_running;check_port_connectivity;check_local_alarms
+	Check string `json:"check,omitempty"`
+	// +kubebuilder:validation:Minimum:=0
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// +kubebuilder:validation:Minimum:=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum:=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// Must be 1 for the liveness and startup probes.
+	// +kubebuilder:validation:Minimum:=1
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+	// +kubebuilder:validation:Minimum:=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// SecurityProfile is a set of security settings of the RabbitMQ Pods
+type SecurityProfile string
+
+const (
+	DefaultSecurityProfile    SecurityProfile = "Default"
+	RestrictedSecurityProfile SecurityProfile = "Restricted"
+)
+
+// Settable attributes for the NetworkPolicy of the RabbitMQ Pods.
+type RabbitmqClusterNetworkPolicySpec struct {
+	// Peers allowed to connect to the client ports: AMQP, the management API and the ports of enabled plugins, including their TLS ports.
+	// Connections from any source are allowed when empty.
+	ClientPeers []networkingv1.NetworkPolicyPeer `json:"clientPeers,omitempty"`
+	// Peers allowed to scrape the Prometheus port 15692.
+	// Connections from any source are allowed when empty.
+	PrometheusPeers []networkingv1.NetworkPolicyPeer `json:"prometheusPeers,omitempty"`
+}
+
+// Settable attributes for the exposure of the management UI.
+type RabbitmqClusterManagementSpec struct {
+	// Ingress routes HTTP traffic for a host to a management-only Service, so that the client Service can remain of type ClusterIP.
+	Ingress *RabbitmqClusterManagementIngressSpec `json:"ingress,omitempty"`
+}
+
+// Settable attributes for the Ingress or HTTPRoute to the management UI.
+type RabbitmqClusterManagementIngressSpec struct {
+	// Kind of the resource routing to the management UI. Defaults to Ingress.
+	// HTTPRoute requires the Gateway API CRDs to be installed in the Kubernetes cluster.
+	// +kubebuilder:validation:Enum=Ingress;HTTPRoute
+	Kind string `json:"kind,omitempty"`
+	// Host name the management UI is served on.
+	// +kubebuilder:validation:MinLength:=1
+	Host string `json:"host"`
+	// Path the management UI is served under, e.g. /rabbitmq. Defaults to /.
+	// A path other than / is also set as management.path_prefix in rabbitmq.conf.
+	// +kubebuilder:validation:Pattern:=^/.*
+	Path string `json:"path,omitempty"`
+	// Name of the Secret holding the certificate for Host. Only used by Ingress; TLS of an HTTPRoute is configured on its Gateway.
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// Name of the IngressClass. Only used by Ingress.
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Annotations to add to the Ingress or HTTPRoute, e.g. to configure the ingress controller.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Gateway the HTTPRoute attaches to. Required when Kind is HTTPRoute.
+	Gateway *RabbitmqClusterGatewayReference `json:"gateway,omitempty"`
+}
+
+// Reference to a Gateway API Gateway.
+type RabbitmqClusterGatewayReference struct {
+	Name string `json:"name"`
+	// Namespace of the Gateway. Defaults to the namespace of the RabbitmqCluster.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Settable attributes for the scheduling of RabbitMQ Pods.
+// When set, even if empty, Pods prefer not to share a node and are spread evenly across zones.
+type RabbitmqClusterPlacementSpec struct {
+	// Weight of the preferred pod anti-affinity by hostname. Defaults to 100.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	AntiAffinityWeight int32 `json:"antiAffinityWeight,omitempty"`
+	// Node label RabbitMQ Pods are spread across. Defaults to topology.kubernetes.io/zone.
+	ZoneTopologyKey string `json:"zoneTopologyKey,omitempty"`
+	// Maximum difference in the number of RabbitMQ Pods between two zones. Defaults to 1.
+	// +kubebuilder:validation:Minimum:=1
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+	// Whether Pods that would violate the spread are still scheduled. Defaults to ScheduleAnyway.
+	// +kubebuilder:validation:Enum=ScheduleAnyway;DoNotSchedule
+	WhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"whenUnsatisfiable,omitempty"`
+}
+
+// Settable attributes for the Prometheus Operator resources.
+type RabbitmqClusterMonitoringSpec struct {
+	// Kind of the resource used to scrape the RabbitMQ Prometheus endpoint on port 15692. Defaults to PodMonitor.
+	// +kubebuilder:validation:Enum=PodMonitor;ServiceMonitor
+	MonitorKind string `json:"monitorKind,omitempty"`
+	// Interval at which metrics are scraped, e.g. 15s. Defaults to the Prometheus global scrape interval.
+	Interval string `json:"interval,omitempty"`
+	// Labels to add to the monitor and the PrometheusRule, so that they match the selectors of the Prometheus instance.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Set to true to skip creating the PrometheusRule with the default alerts.
+	DisableAlerts bool `json:"disableAlerts,omitempty"`
 }
 
 type RabbitmqClusterOverrideSpec struct {
-	StatefulSet   *StatefulSet   `json:"statefulSet,omitempty"`
-	ClientService *ClientService `json:"clientService,omitempty"`
+	StatefulSet         *StatefulSet         `json:"statefulSet,omitempty"`
+	ClientService       *ClientService       `json:"clientService,omitempty"`
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
+
+type PodDisruptionBudget struct {
+	// +optional
+	*EmbeddedLabelsAnnotations `json:"metadata,omitempty"`
+	// Spec defines the disruption budget of the RabbitMQ Pods.
+	// Defaults to a maxUnavailable of (replicas-1)/2, so that a majority of the nodes stays available, and at least 1, so that node drains are not blocked.
+	// +optional
+	Spec *PodDisruptionBudgetSpec `json:"spec,omitempty"`
+}
+
+// PodDisruptionBudgetSpec contains a subset of the fields included in k8s.io/api/policy/v1beta1.PodDisruptionBudgetSpec.
+// Field Selector is omitted; the budget always applies to the Pods of the RabbitmqCluster.
+// At most one of MinAvailable and MaxUnavailable can be set.
+type PodDisruptionBudgetSpec struct {
+	// An eviction is allowed if at least "minAvailable" RabbitMQ Pods
+	// will still be available after the eviction.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// An eviction is allowed if at most "maxUnavailable" RabbitMQ Pods
+	// are unavailable after the eviction.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 type ClientService struct {
@@ -145,7 +341,7 @@ type StatefulSetSpec struct {
 	UpdateStrategy *appsv1.StatefulSetUpdateStrategy `json:"updateStrategy,omitempty" protobuf:"bytes,7,opt,name=updateStrategy"`
 }
 
-// It is used in ClientService and StatefulSet
+// It is used in ClientService, StatefulSet and PodDisruptionBudget
 type EmbeddedLabelsAnnotations struct {
 	// Map of string keys and values that can be used to organize and categorize
 	// (scope and select) objects. May match selectors of replication controllers
@@ -246,11 +442,52 @@ type TLSSpec struct {
 // +kubebuilder:validation:MaxLength=100
 type Plugin string
 
+// Definition of a plugin that is not part of the operator's plugin catalog.
+type CommunityPlugin struct {
+	Name Plugin `json:"name"`
+	// Ports the plugin listens on. They are exposed on the RabbitMQ container and the client Service.
+	// +kubebuilder:validation:MaxItems:=10
+	Ports []PluginPort `json:"ports,omitempty"`
+	// Plugins enabled along with this plugin. Their ports are only exposed if they are listed in additionalPlugins as well.
+	Dependencies []Plugin `json:"dependencies,omitempty"`
+	// Where to fetch the plugin from. Exactly one of URL and Image must be set.
+	Source *CommunityPluginSource `json:"source,omitempty"`
+}
+
+type CommunityPluginSource struct {
+	// URL of the .ez file of the plugin, downloaded by the setup-container on Pod startup.
+	URL string `json:"url,omitempty"`
+	// Hex-encoded SHA-256 checksum of the file at URL. Required with URL.
+	// +kubebuilder:validation:Pattern:="^[0-9a-fA-F]{64}$"
+	SHA256 string `json:"sha256,omitempty"`
+	// OCI image containing the .ez files of the plugin. The image must provide sh and cp.
+	Image string `json:"image,omitempty"`
+	// Directory of the .ez files in Image. Defaults to /plugins.
+	Path string `json:"path,omitempty"`
+}
+
+type PluginPort struct {
+	// Name of the container and Service port.
+	// +kubebuilder:validation:MaxLength:=15
+	Name string `json:"name"`
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=65535
+	Port int32 `json:"port"`
+	// Set to true if the port only serves TLS connections; it is then only exposed when spec.tls is set.
+	TLS bool `json:"tls,omitempty"`
+	// rabbitmq.conf key configuring the listener on this port, e.g. mqtt.listeners.ssl.default. Optional.
+	ConfigKey string `json:"configKey,omitempty"`
+}
+
 // Rabbitmq related configurations
 type RabbitmqClusterConfigurationSpec struct {
 	// List of plugins to enable in addition to essential plugins: rabbitmq_management, rabbitmq_prometheus, and rabbitmq_peer_discovery_k8s.
 	// +kubebuilder:validation:MaxItems:=100
 	AdditionalPlugins []Plugin `json:"additionalPlugins,omitempty"`
+	// Ports, dependencies and sources of plugins the operator does not know about, such as community plugins.
+	// A plugin with a source is fetched by the operator and enabled. A plugin without a source must be included in the image and listed in AdditionalPlugins to be enabled.
+	// +kubebuilder:validation:MaxItems:=100
+	CommunityPlugins []CommunityPlugin `json:"communityPlugins,omitempty"`
 	// Modify to add to the rabbitmq.conf file in addition to default configurations set by the operator. Modifying this property on an existing RabbitmqCluster will trigger a StatefulSet rolling restart and will cause rabbitmq downtime.
 	// +kubebuilder:validation:MaxLength:=2000
 	AdditionalConfig string `json:"additionalConfig,omitempty"`
@@ -265,9 +502,77 @@ type RabbitmqClusterConfigurationSpec struct {
 // The settings for the persistent storage desired for each Pod in the RabbitmqCluster.
 type RabbitmqClusterPersistenceSpec struct {
 	// StorageClassName is the name of the StorageClass to claim a PersistentVolume from.
+	// Changing it on an existing RabbitmqCluster of at least 3 replicas moves the nodes to new, empty PersistentVolumes one at a time;
+	// the progress is reported in status.storageMigration. Only quorum queues are replicated back to the moved nodes:
+	// the classic queues and stream replicas of each node are lost.
 	StorageClassName *string `json:"storageClassName,omitempty"`
 	// The requested size of the persistent volume attached to each Pod in the RabbitmqCluster.
+	// A size of 0 disables persistence, like setting Enabled to false.
 	Storage *k8sresource.Quantity `json:"storage,omitempty"`
+	// Set to false to store RabbitMQ data in an emptyDir volume instead of a PersistentVolumeClaim, e.g. for development and test clusters.
+	// Data is lost whenever a Pod is deleted. Defaults to true. Changing it once the RabbitmqCluster is created has no effect, and is reported by the NoWarnings condition.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Storage medium of the emptyDir volume used when persistence is disabled.
+	// Set to Memory for a tmpfs volume, whose content counts against the memory limit of the RabbitMQ container.
+	// +kubebuilder:validation:Enum=Memory
+	EmptyDirMedium corev1.StorageMedium `json:"emptyDirMedium,omitempty"`
+	// RetentionPolicy controls whether the PersistentVolumeClaims of the RabbitmqCluster are kept when it is deleted or scaled down.
+	RetentionPolicy *PersistentVolumeClaimRetentionPolicy `json:"retentionPolicy,omitempty"`
+	// RestoreFrom is a RabbitmqSnapshot, in the namespace of the RabbitmqCluster, to provision the PersistentVolumeClaims of a new RabbitmqCluster from.
+	// The RabbitmqSnapshot must have been taken from a RabbitmqCluster of the same name and number of replicas.
+	// Only used when the StatefulSet is created.
+	RestoreFrom *RabbitmqSnapshotReference `json:"restoreFrom,omitempty"`
+	// AdditionalVolumes are further PersistentVolumes attached to each Pod, each holding part of the RabbitMQ data on its own disk.
+	// Cannot be changed once the RabbitmqCluster is created.
+	AdditionalVolumes []RabbitmqClusterAdditionalVolume `json:"additionalVolumes,omitempty"`
+}
+
+type RabbitmqSnapshotReference struct {
+	// Name of the RabbitmqSnapshot.
+	Name string `json:"name"`
+}
+
+// +kubebuilder:validation:Enum=QuorumWAL;QuorumSegments;ClassicMessageStore
+type AdditionalVolumeContent string
+
+const (
+	// The write-ahead log of quorum queues, configured as raft.wal_data_dir.
+	QuorumWALVolumeContent AdditionalVolumeContent = "QuorumWAL"
+	// The segment files of quorum queues, configured as RABBITMQ_QUORUM_DIR.
+	QuorumSegmentsVolumeContent AdditionalVolumeContent = "QuorumSegments"
+	// The message store of classic queues, linked from msg_stores in the node data directory.
+	ClassicMessageStoreVolumeContent AdditionalVolumeContent = "ClassicMessageStore"
+)
+
+// Settable attributes for an additional PersistentVolume of each RabbitMQ Pod.
+type RabbitmqClusterAdditionalVolume struct {
+	// Name of the volume claim template. The volume is mounted at /var/lib/rabbitmq/<name>.
+	// +kubebuilder:validation:Pattern:=^[a-z]([-a-z0-9]*[a-z0-9])?$
+	// +kubebuilder:validation:MaxLength:=40
+	Name string `json:"name"`
+	// StorageClassName is the name of the StorageClass to claim the PersistentVolume from.
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// The requested size of the PersistentVolume.
+	Storage k8sresource.Quantity `json:"storage"`
+	// The RabbitMQ data stored on the volume.
+	Content AdditionalVolumeContent `json:"content"`
+}
+
+// +kubebuilder:validation:Enum=Retain;Delete
+type PersistentVolumeClaimRetentionPolicyType string
+
+const (
+	// The PersistentVolumeClaims are kept, and reused if Pods with the same names are created again.
+	RetainPersistentVolumeClaimRetentionPolicyType PersistentVolumeClaimRetentionPolicyType = "Retain"
+	// The PersistentVolumeClaims are deleted by the operator.
+	DeletePersistentVolumeClaimRetentionPolicyType PersistentVolumeClaimRetentionPolicyType = "Delete"
+)
+
+type PersistentVolumeClaimRetentionPolicy struct {
+	// What happens to the PersistentVolumeClaims when the RabbitmqCluster is deleted. Defaults to Delete.
+	WhenDeleted PersistentVolumeClaimRetentionPolicyType `json:"whenDeleted,omitempty"`
+	// What happens to the PersistentVolumeClaims of the removed Pods when the RabbitmqCluster is scaled down. Defaults to Retain.
+	WhenScaled PersistentVolumeClaimRetentionPolicyType `json:"whenScaled,omitempty"`
 }
 
 // Settable attributes for the Client Service resource.
@@ -278,14 +583,126 @@ type RabbitmqClusterServiceSpec struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// Settable attributes for an additional Service resource.
+type RabbitmqClusterAdditionalServiceSpec struct {
+	// Name of the Service, which is created as <RabbitmqCluster name>-rabbitmq-<name>.
+	// The names client, headless, management and server are reserved.
+	// +kubebuilder:validation:Pattern:=^[a-z]([-a-z0-9]*[a-z0-9])?$
+	// +kubebuilder:validation:MaxLength:=40
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer;NodePort
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations to add to the Service.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Names of the client Service ports to expose, e.g. amqps or mqtt. The prometheus port is only exposed by the client Service.
+	// +kubebuilder:validation:MinItems:=1
+	Ports []string `json:"ports"`
+}
+
+// Settable attributes for the per-pod Service resources.
+type RabbitmqClusterPerPodServiceSpec struct {
+	// Type of the per-pod Services. Defaults to LoadBalancer.
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations to add to the per-pod Services.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// DNS domain under which the per-pod Services are reachable from outside the Kubernetes cluster, e.g. rabbitmq.example.com.
+	// When rabbitmq_stream is enabled, each node advertises <pod name>.<externalDomain> to stream clients.
+	ExternalDomain string `json:"externalDomain,omitempty"`
+}
+
 // Status presents the observed state of RabbitmqCluster
 type RabbitmqClusterStatus struct {
+	// Lifecycle phase of the RabbitmqCluster: Creating, Initializing, Running, Updating, Upgrading, ScalingDown, MigratingStorage, Degraded, Deleting or Failed.
 	ClusterStatus string `json:"clusterStatus,omitempty"`
+	// Time the RabbitmqCluster reached the Running phase for the first time.
+	// Once set, the cluster no longer goes back to the Initializing phase.
+	FirstRunningTime *metav1.Time `json:"firstRunningTime,omitempty"`
 	// Set of Conditions describing the current state of the RabbitmqCluster
 	Conditions []status.RabbitmqClusterCondition `json:"conditions"`
 
+	// The .metadata.generation of the RabbitmqCluster last processed by the operator.
+	// The status reflects the latest spec when it equals .metadata.generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Identifying information on internal resources
 	Admin *RabbitmqClusterAdmin `json:"admin,omitempty"`
+
+	// Addresses clients connect to, for each port of the client Service and of the additional Services.
+	Endpoints []RabbitmqClusterEndpoint `json:"endpoints,omitempty"`
+
+	// Cluster-wide information reported by the RabbitMQ management API.
+	Overview *RabbitmqClusterOverview `json:"overview,omitempty"`
+
+	// State of each RabbitMQ node as reported by the RabbitMQ management API.
+	Nodes []RabbitmqNodeStatus `json:"nodes,omitempty"`
+
+	// Progress of moving the nodes to PersistentVolumes of a new StorageClass, while spec.persistence.storageClassName is being changed.
+	StorageMigration *RabbitmqClusterStorageMigrationStatus `json:"storageMigration,omitempty"`
+}
+
+// StorageMigrationStep is the step a node being moved to a new PersistentVolume is at.
+type StorageMigrationStep string
+
+const (
+	// The node is put into maintenance mode, transferring its queue leaders and client connections to other nodes.
+	DrainStorageMigrationStep StorageMigrationStep = "Drain"
+	// The RabbitMQ application is stopped on the node, and the node is removed from the cluster.
+	ForgetStorageMigrationStep StorageMigrationStep = "Forget"
+	// The PersistentVolumeClaim and the Pod of the node are deleted.
+	ReplaceStorageMigrationStep StorageMigrationStep = "Replace"
+	// The recreated node joins the cluster on its new PersistentVolume and becomes a member of all quorum queues.
+	RejoinStorageMigrationStep StorageMigrationStep = "Rejoin"
+	// The quorum queues catch up with the rejoined node.
+	SyncStorageMigrationStep StorageMigrationStep = "Sync"
+)
+
+type RabbitmqClusterStorageMigrationStatus struct {
+	// StorageClass the nodes are moved to; empty for the default StorageClass.
+	StorageClassName string `json:"storageClassName"`
+	// Pods already running on a PersistentVolume of the new StorageClass.
+	MigratedNodes []string `json:"migratedNodes,omitempty"`
+	// Pod currently being moved.
+	CurrentNode string `json:"currentNode,omitempty"`
+	// Step the current node is at: Drain, Forget, Replace, Rejoin or Sync.
+	Step StorageMigrationStep `json:"step,omitempty"`
+}
+
+type RabbitmqClusterEndpoint struct {
+	// Name of the Service port, e.g. amqp, amqps or mqtt.
+	Protocol string `json:"protocol"`
+	// Name of the Service exposing the port.
+	Service string `json:"service"`
+	// DNS name of the Service inside the Kubernetes cluster.
+	Host string `json:"host"`
+	Port int32  `json:"port"`
+	// Port allocated on every Kubernetes node, for NodePort and LoadBalancer Services.
+	NodePort int32 `json:"nodePort,omitempty"`
+	// IP addresses or host names of the load balancer, for LoadBalancer Services.
+	LoadBalancerIngress []string `json:"loadBalancerIngress,omitempty"`
+}
+
+type RabbitmqClusterOverview struct {
+	// Name of the RabbitMQ cluster, as set by cluster_name in rabbitmq.conf.
+	ClusterName     string `json:"clusterName,omitempty"`
+	RabbitmqVersion string `json:"rabbitmqVersion,omitempty"`
+	ErlangVersion   string `json:"erlangVersion,omitempty"`
+	// Number of client connections across all nodes.
+	Connections int64 `json:"connections"`
+	// Number of queues across all virtual hosts.
+	Queues int64 `json:"queues"`
+	// Total number of messages held in queues, ready and unacknowledged.
+	Messages               int64 `json:"messages"`
+	MessagesReady          int64 `json:"messagesReady"`
+	MessagesUnacknowledged int64 `json:"messagesUnacknowledged"`
+}
+
+type RabbitmqNodeStatus struct {
+	// Erlang node name, e.g. rabbit@my-cluster-rabbitmq-server-0.my-cluster-rabbitmq-headless.my-namespace
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	// Time in seconds since the node was started.
+	UptimeSeconds int64 `json:"uptimeSeconds,omitempty"`
 }
 
 type RabbitmqClusterAdmin struct {
@@ -316,6 +733,140 @@ func (cluster *RabbitmqCluster) SingleTLSSecret() bool {
 	return cluster.MutualTLSEnabled() && cluster.Spec.TLS.CaSecretName == cluster.Spec.TLS.SecretName
 }
 
+func (cluster *RabbitmqCluster) PerPodServiceEnabled() bool {
+	return cluster.Spec.PerPodService != nil
+}
+
+func (cluster *RabbitmqCluster) StreamEnabled() bool {
+	return cluster.AdditionalPluginEnabled("rabbitmq_stream")
+}
+
+func (cluster *RabbitmqCluster) ManagementIngressEnabled() bool {
+	return cluster.Spec.Management != nil && cluster.Spec.Management.Ingress != nil
+}
+
+func (cluster *RabbitmqCluster) ManagementHTTPRouteEnabled() bool {
+	return cluster.ManagementIngressEnabled() && cluster.Spec.Management.Ingress.Kind == "HTTPRoute"
+}
+
+// PersistenceEnabled reports whether RabbitMQ data is stored in a PersistentVolumeClaim rather than an emptyDir volume
+func (cluster *RabbitmqCluster) PersistenceEnabled() bool {
+	persistence := cluster.Spec.Persistence
+	if persistence.Enabled != nil && !*persistence.Enabled {
+		return false
+	}
+	return persistence.Storage == nil || !persistence.Storage.IsZero()
+}
+
+// PVCRetentionWhenDeleted returns what happens to the PersistentVolumeClaims when the RabbitmqCluster is deleted
+func (cluster *RabbitmqCluster) PVCRetentionWhenDeleted() PersistentVolumeClaimRetentionPolicyType {
+	if policy := cluster.Spec.Persistence.RetentionPolicy; policy != nil && policy.WhenDeleted != "" {
+		return policy.WhenDeleted
+	}
+	return DeletePersistentVolumeClaimRetentionPolicyType
+}
+
+// PVCRetentionWhenScaled returns what happens to the PersistentVolumeClaims of removed Pods when the RabbitmqCluster is scaled down
+func (cluster *RabbitmqCluster) PVCRetentionWhenScaled() PersistentVolumeClaimRetentionPolicyType {
+	if policy := cluster.Spec.Persistence.RetentionPolicy; policy != nil && policy.WhenScaled != "" {
+		return policy.WhenScaled
+	}
+	return RetainPersistentVolumeClaimRetentionPolicyType
+}
+
+// TerminationGracePeriodSeconds returns the time RabbitMQ nodes are given to stop gracefully
+func (cluster *RabbitmqCluster) TerminationGracePeriodSeconds() int64 {
+	if cluster.Spec.TerminationGracePeriodSeconds != nil {
+		return *cluster.Spec.TerminationGracePeriodSeconds
+	}
+	return defaultTerminationGracePeriodSeconds
+}
+
+// PreStopTimeoutSeconds returns the time each step of the pre-stop hook may take.
+// When spec.terminationGracePeriodSeconds or spec.preStop is set, all steps together fit within the termination grace period;
+// otherwise each step keeps the default grace period, so that the Pod template of existing clusters does not change.
+func (cluster *RabbitmqCluster) PreStopTimeoutSeconds() int64 {
+	if cluster.Spec.TerminationGracePeriodSeconds == nil && cluster.Spec.PreStop == nil {
+		return defaultTerminationGracePeriodSeconds
+	}
+
+	steps := int64(0)
+	for _, enabled := range []bool{cluster.PreStopDrain(), cluster.PreStopAwaitQuorumPlusOne(), cluster.PreStopAwaitSynchronizedMirror()} {
+		if enabled {
+			steps++
+		}
+	}
+	timeout := cluster.TerminationGracePeriodSeconds()
+	if steps > 0 {
+		timeout /= steps
+	}
+
+	if preStop := cluster.Spec.PreStop; preStop != nil && preStop.TimeoutSeconds != nil && *preStop.TimeoutSeconds < timeout {
+		timeout = *preStop.TimeoutSeconds
+	}
+	// rabbitmq-upgrade does not accept a timeout of 0
+	if timeout < minPreStopTimeoutSeconds {
+		return minPreStopTimeoutSeconds
+	}
+	return timeout
+}
+
+// PreStopAwaitQuorumPlusOne reports whether the pre-stop hook waits for quorum queues to have a replica more than their quorum online
+func (cluster *RabbitmqCluster) PreStopAwaitQuorumPlusOne() bool {
+	preStop := cluster.Spec.PreStop
+	return preStop == nil || preStop.AwaitQuorumPlusOne == nil || *preStop.AwaitQuorumPlusOne
+}
+
+// PreStopAwaitSynchronizedMirror reports whether the pre-stop hook waits for classic mirrored queues to have a synchronised mirror
+func (cluster *RabbitmqCluster) PreStopAwaitSynchronizedMirror() bool {
+	preStop := cluster.Spec.PreStop
+	return preStop == nil || preStop.AwaitSynchronizedMirror == nil || *preStop.AwaitSynchronizedMirror
+}
+
+// PreStopDrain reports whether the pre-stop hook puts the node into maintenance mode first
+func (cluster *RabbitmqCluster) PreStopDrain() bool {
+	return cluster.Spec.PreStop != nil && cluster.Spec.PreStop.Drain
+}
+
+// RestrictedSecurityProfile reports whether the RabbitMQ Pods run unprivileged, as the user assigned by the namespace
+func (cluster *RabbitmqCluster) RestrictedSecurityProfile() bool {
+	return cluster.Spec.SecurityProfile == RestrictedSecurityProfile
+}
+
+func (cluster *RabbitmqCluster) NetworkPolicyEnabled() bool {
+	return cluster.Spec.NetworkPolicy != nil
+}
+
+func (cluster *RabbitmqCluster) MonitoringEnabled() bool {
+	return cluster.Spec.Monitoring != nil
+}
+
+func (cluster *RabbitmqCluster) ServiceMonitorEnabled() bool {
+	return cluster.MonitoringEnabled() && cluster.Spec.Monitoring.MonitorKind == "ServiceMonitor"
+}
+
+// FetchedCommunityPlugins returns the community plugins the operator fetches from their source.
+func (cluster *RabbitmqCluster) FetchedCommunityPlugins() []CommunityPlugin {
+	var plugins []CommunityPlugin
+	for _, plugin := range cluster.Spec.Rabbitmq.CommunityPlugins {
+		if plugin.Source != nil {
+			plugins = append(plugins, plugin)
+		}
+	}
+	return plugins
+}
+
+// EnabledAdditionalPlugins returns the additional plugins and the fetched community plugins.
+func (cluster *RabbitmqCluster) EnabledAdditionalPlugins() []Plugin {
+	plugins := append([]Plugin{}, cluster.Spec.Rabbitmq.AdditionalPlugins...)
+	for _, plugin := range cluster.FetchedCommunityPlugins() {
+		if !cluster.AdditionalPluginEnabled(plugin.Name) {
+			plugins = append(plugins, plugin.Name)
+		}
+	}
+	return plugins
+}
+
 func (cluster *RabbitmqCluster) AdditionalPluginEnabled(plugin Plugin) bool {
 	for _, p := range cluster.Spec.Rabbitmq.AdditionalPlugins {
 		if p == plugin {
@@ -330,6 +881,9 @@ func (clusterStatus *RabbitmqClusterStatus) SetConditions(resources []runtime.Ob
 	var oldClusterAvailableCondition *status.RabbitmqClusterCondition
 	var oldNoWarningsCondition *status.RabbitmqClusterCondition
 	var oldReconcileCondition *status.RabbitmqClusterCondition
+	var oldNoNetworkPartitionsCondition *status.RabbitmqClusterCondition
+	var oldNoResourceAlarmsCondition *status.RabbitmqClusterCondition
+	var oldReadyCondition *status.RabbitmqClusterCondition
 
 	for _, condition := range clusterStatus.Conditions {
 		switch condition.Type {
@@ -341,6 +895,12 @@ func (clusterStatus *RabbitmqClusterStatus) SetConditions(resources []runtime.Ob
 			oldNoWarningsCondition = condition.DeepCopy()
 		case status.ReconcileSuccess:
 			oldReconcileCondition = condition.DeepCopy()
+		case status.NoNetworkPartitions:
+			oldNoNetworkPartitionsCondition = condition.DeepCopy()
+		case status.NoResourceAlarms:
+			oldNoResourceAlarmsCondition = condition.DeepCopy()
+		case status.Ready:
+			oldReadyCondition = condition.DeepCopy()
 		}
 	}
 
@@ -355,11 +915,34 @@ func (clusterStatus *RabbitmqClusterStatus) SetConditions(resources []runtime.Ob
 		reconciledCondition = status.ReconcileSuccessCondition(corev1.ConditionUnknown, "Initialising", "")
 	}
 
+	// node conditions are only refreshed from the management API, see status.UpdateNodeConditions
+	var noNetworkPartitionsCond status.RabbitmqClusterCondition
+	if oldNoNetworkPartitionsCondition != nil {
+		noNetworkPartitionsCond = *oldNoNetworkPartitionsCondition
+	} else {
+		noNetworkPartitionsCond = status.NoNetworkPartitionsCondition(nil, nil)
+	}
+
+	var noResourceAlarmsCond status.RabbitmqClusterCondition
+	if oldNoResourceAlarmsCondition != nil {
+		noResourceAlarmsCond = *oldNoResourceAlarmsCondition
+	} else {
+		noResourceAlarmsCond = status.NoResourceAlarmsCondition(nil, nil)
+	}
+
 	clusterStatus.Conditions = []status.RabbitmqClusterCondition{
 		allReplicasReadyCond,
 		clusterAvailableCond,
 		noWarningsCond,
 		reconciledCondition,
+		noNetworkPartitionsCond,
+		noResourceAlarmsCond,
+	}
+	clusterStatus.Conditions = append(clusterStatus.Conditions, status.ReadyCondition(clusterStatus.Conditions, oldReadyCondition))
+
+	// conditions are restamped once the latest generation has been reconciled, see SetObservedGeneration
+	for i := range clusterStatus.Conditions {
+		clusterStatus.Conditions[i].ObservedGeneration = clusterStatus.ObservedGeneration
 	}
 }
 
@@ -372,6 +955,23 @@ func (clusterStatus *RabbitmqClusterStatus) SetCondition(condType status.Rabbitm
 			break
 		}
 	}
+
+	// keep the aggregated Ready condition in sync with the condition it depends on
+	for i := range clusterStatus.Conditions {
+		if clusterStatus.Conditions[i].Type == status.Ready && condType != status.Ready {
+			oldReadyCondition := clusterStatus.Conditions[i].DeepCopy()
+			clusterStatus.Conditions[i] = status.ReadyCondition(clusterStatus.Conditions, oldReadyCondition)
+			break
+		}
+	}
+}
+
+// SetObservedGeneration records that the status, including every condition, reflects the given RabbitmqCluster generation.
+func (clusterStatus *RabbitmqClusterStatus) SetObservedGeneration(generation int64) {
+	clusterStatus.ObservedGeneration = generation
+	for i := range clusterStatus.Conditions {
+		clusterStatus.Conditions[i].ObservedGeneration = generation
+	}
 }
 
 // +kubebuilder:object:root=true
@@ -112,6 +112,67 @@ var _ = Describe("RabbitmqCluster", func() {
 			Expect(created.MutualTLSEnabled()).To(BeTrue())
 		})
 
+		It("can be queried if persistence is enabled", func() {
+			created := generateRabbitmqClusterObject("rabbit-persistence")
+			Expect(created.PersistenceEnabled()).To(BeTrue())
+
+			disabled := false
+			created.Spec.Persistence.Enabled = &disabled
+			Expect(created.PersistenceEnabled()).To(BeFalse())
+
+			created.Spec.Persistence.Enabled = nil
+			zero := k8sresource.MustParse("0Gi")
+			created.Spec.Persistence.Storage = &zero
+			Expect(created.PersistenceEnabled()).To(BeFalse())
+		})
+
+		It("can be queried for the PersistentVolumeClaim retention policy", func() {
+			created := generateRabbitmqClusterObject("rabbit-retention")
+			Expect(created.PVCRetentionWhenDeleted()).To(Equal(DeletePersistentVolumeClaimRetentionPolicyType))
+			Expect(created.PVCRetentionWhenScaled()).To(Equal(RetainPersistentVolumeClaimRetentionPolicyType))
+
+			created.Spec.Persistence.RetentionPolicy = &PersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: RetainPersistentVolumeClaimRetentionPolicyType,
+				WhenScaled:  DeletePersistentVolumeClaimRetentionPolicyType,
+			}
+			Expect(created.PVCRetentionWhenDeleted()).To(Equal(RetainPersistentVolumeClaimRetentionPolicyType))
+			Expect(created.PVCRetentionWhenScaled()).To(Equal(DeletePersistentVolumeClaimRetentionPolicyType))
+		})
+
+		It("can be queried for the termination grace period and the preStop policy", func() {
+			created := generateRabbitmqClusterObject("rabbit-prestop")
+			Expect(created.TerminationGracePeriodSeconds()).To(Equal(int64(604800)))
+			Expect(created.PreStopTimeoutSeconds()).To(Equal(int64(604800)))
+			Expect(created.PreStopDrain()).To(BeFalse())
+			Expect(created.PreStopAwaitQuorumPlusOne()).To(BeTrue())
+			Expect(created.PreStopAwaitSynchronizedMirror()).To(BeTrue())
+
+			gracePeriod := int64(3600)
+			created.Spec.TerminationGracePeriodSeconds = &gracePeriod
+			Expect(created.PreStopTimeoutSeconds()).To(Equal(int64(1800)))
+
+			timeout := int64(600)
+			created.Spec.PreStop = &RabbitmqClusterPreStopSpec{
+				Drain:                   true,
+				AwaitSynchronizedMirror: new(bool),
+				TimeoutSeconds:          &timeout,
+			}
+			Expect(created.PreStopTimeoutSeconds()).To(Equal(int64(600)))
+			Expect(created.PreStopDrain()).To(BeTrue())
+			Expect(created.PreStopAwaitQuorumPlusOne()).To(BeTrue())
+			Expect(created.PreStopAwaitSynchronizedMirror()).To(BeFalse())
+
+			By("capping the timeout so that all steps fit within the termination grace period", func() {
+				timeout = int64(3000)
+				Expect(created.PreStopTimeoutSeconds()).To(Equal(int64(1800)))
+			})
+
+			By("never rendering a timeout of 0", func() {
+				gracePeriod = 0
+				Expect(created.PreStopTimeoutSeconds()).To(Equal(int64(1)))
+			})
+		})
+
 		It("is validated", func() {
 			By("checking the replica count", func() {
 				nOne := int32(-1)
@@ -316,11 +377,58 @@ var _ = Describe("RabbitmqCluster", func() {
 
 			rabbitmqClusterStatus.SetConditions([]runtime.Object{statefulset, endPoints})
 
-			Expect(rabbitmqClusterStatus.Conditions).To(HaveLen(4))
+			Expect(rabbitmqClusterStatus.Conditions).To(HaveLen(7))
 			Expect(rabbitmqClusterStatus.Conditions[0].Type).To(Equal(status.AllReplicasReady))
 			Expect(rabbitmqClusterStatus.Conditions[1].Type).To(Equal(status.ClusterAvailable))
 			Expect(rabbitmqClusterStatus.Conditions[2].Type).To(Equal(status.NoWarnings))
 			Expect(rabbitmqClusterStatus.Conditions[3].Type).To(Equal(status.ReconcileSuccess))
+			Expect(rabbitmqClusterStatus.Conditions[4].Type).To(Equal(status.NoNetworkPartitions))
+			Expect(rabbitmqClusterStatus.Conditions[5].Type).To(Equal(status.NoResourceAlarms))
+			Expect(rabbitmqClusterStatus.Conditions[4].Status).To(Equal(corev1.ConditionUnknown))
+			Expect(rabbitmqClusterStatus.Conditions[5].Status).To(Equal(corev1.ConditionUnknown))
+			Expect(rabbitmqClusterStatus.Conditions[6].Type).To(Equal(status.Ready))
+		})
+
+		It("keeps the Ready condition in sync when a condition is updated", func() {
+			rabbitmqClusterStatus := RabbitmqClusterStatus{}
+			rabbitmqClusterStatus.SetConditions([]runtime.Object{})
+			Expect(rabbitmqClusterStatus.Conditions[6].Type).To(Equal(status.Ready))
+			Expect(rabbitmqClusterStatus.Conditions[6].Reason).NotTo(Equal("ReconcileNotSuccessful"))
+
+			rabbitmqClusterStatus.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "Error", "some error")
+			Expect(rabbitmqClusterStatus.Conditions[6].Status).To(Equal(corev1.ConditionFalse))
+			Expect(rabbitmqClusterStatus.Conditions[6].Reason).To(Equal("ReconcileNotSuccessful"))
+		})
+
+		It("records the observed generation", func() {
+			rabbitmqClusterStatus := RabbitmqClusterStatus{}
+			rabbitmqClusterStatus.SetConditions([]runtime.Object{})
+			rabbitmqClusterStatus.SetObservedGeneration(3)
+
+			Expect(rabbitmqClusterStatus.ObservedGeneration).To(Equal(int64(3)))
+			for _, condition := range rabbitmqClusterStatus.Conditions {
+				Expect(condition.ObservedGeneration).To(Equal(int64(3)))
+			}
+
+			By("preserving it when conditions are recomputed", func() {
+				rabbitmqClusterStatus.SetConditions([]runtime.Object{})
+				for _, condition := range rabbitmqClusterStatus.Conditions {
+					Expect(condition.ObservedGeneration).To(Equal(int64(3)))
+				}
+			})
+		})
+
+		It("preserves node conditions when other conditions are recomputed", func() {
+			rabbitmqClusterStatus := RabbitmqClusterStatus{}
+			rabbitmqClusterStatus.SetConditions([]runtime.Object{})
+			Expect(rabbitmqClusterStatus.Conditions[4].Type).To(Equal(status.NoNetworkPartitions))
+			Expect(rabbitmqClusterStatus.Conditions[5].Type).To(Equal(status.NoResourceAlarms))
+			rabbitmqClusterStatus.Conditions[4].Status = corev1.ConditionFalse
+			rabbitmqClusterStatus.Conditions[5].Status = corev1.ConditionTrue
+
+			rabbitmqClusterStatus.SetConditions([]runtime.Object{})
+			Expect(rabbitmqClusterStatus.Conditions[4].Status).To(Equal(corev1.ConditionFalse))
+			Expect(rabbitmqClusterStatus.Conditions[5].Status).To(Equal(corev1.ConditionTrue))
 		})
 
 		It("updates an arbitrary condition", func() {
@@ -0,0 +1,106 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// RabbitmqSnapshot is the Schema for the rabbitmqsnapshots API.
+// It takes a VolumeSnapshot of the persistence PersistentVolumeClaim of each node of a RabbitmqCluster.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.rabbitmqClusterReference.name"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="ReadyToUse",type="boolean",JSONPath=".status.readyToUse"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type RabbitmqSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RabbitmqSnapshotSpec   `json:"spec,omitempty"`
+	Status RabbitmqSnapshotStatus `json:"status,omitempty"`
+}
+
+// Spec is the desired state of the RabbitmqSnapshot Custom Resource.
+type RabbitmqSnapshotSpec struct {
+	// RabbitmqCluster in the namespace of the RabbitmqSnapshot whose nodes are snapshotted.
+	RabbitmqClusterReference RabbitmqClusterReference `json:"rabbitmqClusterReference"`
+	// Name of the VolumeSnapshotClass of the VolumeSnapshots. The default VolumeSnapshotClass is used when not set.
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	// Stop the RabbitMQ application on every node before taking the VolumeSnapshots, so that they hold a consistent state of the whole cluster.
+	// Nodes are stopped from the highest ordinal to the lowest, and started again from the lowest once all VolumeSnapshots are taken.
+	// The RabbitmqCluster is unavailable meanwhile.
+	StopNodes bool `json:"stopNodes,omitempty"`
+}
+
+type RabbitmqClusterReference struct {
+	// Name of the RabbitmqCluster.
+	Name string `json:"name"`
+}
+
+// RabbitmqSnapshotPhase is the step a RabbitmqSnapshot is at.
+type RabbitmqSnapshotPhase string
+
+const (
+	// The RabbitmqCluster is not ready to be snapshotted yet.
+	PendingRabbitmqSnapshotPhase RabbitmqSnapshotPhase = "Pending"
+	// The RabbitMQ application is being stopped on each node.
+	StoppingNodesRabbitmqSnapshotPhase RabbitmqSnapshotPhase = "StoppingNodes"
+	// The VolumeSnapshots are being taken.
+	SnapshottingRabbitmqSnapshotPhase RabbitmqSnapshotPhase = "Snapshotting"
+	// The RabbitMQ application is being started again on each node.
+	StartingNodesRabbitmqSnapshotPhase RabbitmqSnapshotPhase = "StartingNodes"
+	// All VolumeSnapshots have been taken.
+	CompletedRabbitmqSnapshotPhase RabbitmqSnapshotPhase = "Completed"
+	// A VolumeSnapshot failed; see status.message.
+	FailedRabbitmqSnapshotPhase RabbitmqSnapshotPhase = "Failed"
+)
+
+// Status presents the observed state of RabbitmqSnapshot
+type RabbitmqSnapshotStatus struct {
+	// Phase of the RabbitmqSnapshot: Pending, StoppingNodes, Snapshotting, StartingNodes, Completed or Failed.
+	Phase RabbitmqSnapshotPhase `json:"phase,omitempty"`
+	// Number of nodes of the RabbitmqCluster when the snapshot was taken.
+	Replicas int32 `json:"replicas,omitempty"`
+	// VolumeSnapshot of each node, in ordinal order.
+	VolumeSnapshots []RabbitmqSnapshotVolume `json:"volumeSnapshots,omitempty"`
+	// Secret holding a copy of the default user credentials of the RabbitmqCluster, in the namespace of the RabbitmqSnapshot.
+	// The users are restored with the data, so a RabbitmqCluster restored from the RabbitmqSnapshot gets these credentials.
+	DefaultUserSecretName string `json:"defaultUserSecretName,omitempty"`
+	// All VolumeSnapshots are ready to provision PersistentVolumeClaims from.
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+	// Why the RabbitmqSnapshot is Pending or Failed.
+	Message string `json:"message,omitempty"`
+}
+
+type RabbitmqSnapshotVolume struct {
+	// Pod of the node.
+	Node string `json:"node"`
+	// PersistentVolumeClaim the VolumeSnapshot is taken from.
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+	// Name of the VolumeSnapshot, in the namespace of the RabbitmqSnapshot.
+	VolumeSnapshotName string `json:"volumeSnapshotName"`
+	// The VolumeSnapshot is ready to provision a PersistentVolumeClaim from.
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RabbitmqSnapshotList contains a list of RabbitmqSnapshot
+type RabbitmqSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RabbitmqSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RabbitmqSnapshot{}, &RabbitmqSnapshotList{})
+}
@@ -0,0 +1,66 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+
+package v1beta1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"golang.org/x/net/context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("RabbitmqSnapshot", func() {
+	generateRabbitmqSnapshotObject := func(name string) *RabbitmqSnapshot {
+		return &RabbitmqSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: RabbitmqSnapshotSpec{
+				RabbitmqClusterReference: RabbitmqClusterReference{Name: "rabbit"},
+			},
+		}
+	}
+
+	It("can be created", func() {
+		created := generateRabbitmqSnapshotObject("snapshot1")
+		className := "csi-hostpath-snapclass"
+		created.Spec.VolumeSnapshotClassName = &className
+		created.Spec.StopNodes = true
+		Expect(k8sClient.Create(context.TODO(), created)).To(Succeed())
+
+		fetched := &RabbitmqSnapshot{}
+		Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: created.Name, Namespace: created.Namespace}, fetched)).To(Succeed())
+		Expect(fetched).To(Equal(created))
+	})
+
+	It("reports the VolumeSnapshots in its status", func() {
+		created := generateRabbitmqSnapshotObject("snapshot2")
+		Expect(k8sClient.Create(context.TODO(), created)).To(Succeed())
+
+		created.Status = RabbitmqSnapshotStatus{
+			Phase:      CompletedRabbitmqSnapshotPhase,
+			Replicas:   1,
+			ReadyToUse: true,
+			VolumeSnapshots: []RabbitmqSnapshotVolume{{
+				Node:                      "rabbit-rabbitmq-server-0",
+				PersistentVolumeClaimName: "persistence-rabbit-rabbitmq-server-0",
+				VolumeSnapshotName:        "snapshot2-0",
+				ReadyToUse:                true,
+			}},
+		}
+		Expect(k8sClient.Status().Update(context.TODO(), created)).To(Succeed())
+
+		fetched := &RabbitmqSnapshot{}
+		Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: created.Name, Namespace: created.Namespace}, fetched)).To(Succeed())
+		Expect(fetched.Status).To(Equal(created.Status))
+	})
+})
@@ -18,8 +18,10 @@ import (
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -47,6 +49,51 @@ func (in *ClientService) DeepCopy() *ClientService {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommunityPlugin) DeepCopyInto(out *CommunityPlugin) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]PluginPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]Plugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(CommunityPluginSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityPlugin.
+func (in *CommunityPlugin) DeepCopy() *CommunityPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(CommunityPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommunityPluginSource) DeepCopyInto(out *CommunityPluginSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityPluginSource.
+func (in *CommunityPluginSource) DeepCopy() *CommunityPluginSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CommunityPluginSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmbeddedLabelsAnnotations) DeepCopyInto(out *EmbeddedLabelsAnnotations) {
 	*out = *in
@@ -123,6 +170,86 @@ func (in *PersistentVolumeClaim) DeepCopy() *PersistentVolumeClaim {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimRetentionPolicy) DeepCopyInto(out *PersistentVolumeClaimRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimRetentionPolicy.
+func (in *PersistentVolumeClaimRetentionPolicy) DeepCopy() *PersistentVolumeClaimRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentVolumeClaimRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginPort) DeepCopyInto(out *PluginPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginPort.
+func (in *PluginPort) DeepCopy() *PluginPort {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudget) DeepCopyInto(out *PodDisruptionBudget) {
+	*out = *in
+	if in.EmbeddedLabelsAnnotations != nil {
+		in, out := &in.EmbeddedLabelsAnnotations, &out.EmbeddedLabelsAnnotations
+		*out = new(EmbeddedLabelsAnnotations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Spec != nil {
+		in, out := &in.Spec, &out.Spec
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudget.
+func (in *PodDisruptionBudget) DeepCopy() *PodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodTemplateSpec) DeepCopyInto(out *PodTemplateSpec) {
 	*out = *in
@@ -175,6 +302,54 @@ func (in *RabbitmqCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterAdditionalServiceSpec) DeepCopyInto(out *RabbitmqClusterAdditionalServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterAdditionalServiceSpec.
+func (in *RabbitmqClusterAdditionalServiceSpec) DeepCopy() *RabbitmqClusterAdditionalServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterAdditionalServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterAdditionalVolume) DeepCopyInto(out *RabbitmqClusterAdditionalVolume) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	out.Storage = in.Storage.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterAdditionalVolume.
+func (in *RabbitmqClusterAdditionalVolume) DeepCopy() *RabbitmqClusterAdditionalVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterAdditionalVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterAdmin) DeepCopyInto(out *RabbitmqClusterAdmin) {
 	*out = *in
@@ -208,6 +383,13 @@ func (in *RabbitmqClusterConfigurationSpec) DeepCopyInto(out *RabbitmqClusterCon
 		*out = make([]Plugin, len(*in))
 		copy(*out, *in)
 	}
+	if in.CommunityPlugins != nil {
+		in, out := &in.CommunityPlugins, &out.CommunityPlugins
+		*out = make([]CommunityPlugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterConfigurationSpec.
@@ -220,6 +402,41 @@ func (in *RabbitmqClusterConfigurationSpec) DeepCopy() *RabbitmqClusterConfigura
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterEndpoint) DeepCopyInto(out *RabbitmqClusterEndpoint) {
+	*out = *in
+	if in.LoadBalancerIngress != nil {
+		in, out := &in.LoadBalancerIngress, &out.LoadBalancerIngress
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterEndpoint.
+func (in *RabbitmqClusterEndpoint) DeepCopy() *RabbitmqClusterEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterGatewayReference) DeepCopyInto(out *RabbitmqClusterGatewayReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterGatewayReference.
+func (in *RabbitmqClusterGatewayReference) DeepCopy() *RabbitmqClusterGatewayReference {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterGatewayReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterList) DeepCopyInto(out *RabbitmqClusterList) {
 	*out = *in
@@ -252,6 +469,109 @@ func (in *RabbitmqClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterManagementIngressSpec) DeepCopyInto(out *RabbitmqClusterManagementIngressSpec) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(RabbitmqClusterGatewayReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterManagementIngressSpec.
+func (in *RabbitmqClusterManagementIngressSpec) DeepCopy() *RabbitmqClusterManagementIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterManagementIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterManagementSpec) DeepCopyInto(out *RabbitmqClusterManagementSpec) {
+	*out = *in
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(RabbitmqClusterManagementIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterManagementSpec.
+func (in *RabbitmqClusterManagementSpec) DeepCopy() *RabbitmqClusterManagementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterManagementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterMonitoringSpec) DeepCopyInto(out *RabbitmqClusterMonitoringSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterMonitoringSpec.
+func (in *RabbitmqClusterMonitoringSpec) DeepCopy() *RabbitmqClusterMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterNetworkPolicySpec) DeepCopyInto(out *RabbitmqClusterNetworkPolicySpec) {
+	*out = *in
+	if in.ClientPeers != nil {
+		in, out := &in.ClientPeers, &out.ClientPeers
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PrometheusPeers != nil {
+		in, out := &in.PrometheusPeers, &out.PrometheusPeers
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterNetworkPolicySpec.
+func (in *RabbitmqClusterNetworkPolicySpec) DeepCopy() *RabbitmqClusterNetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterNetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterOverrideSpec) DeepCopyInto(out *RabbitmqClusterOverrideSpec) {
 	*out = *in
@@ -265,6 +585,11 @@ func (in *RabbitmqClusterOverrideSpec) DeepCopyInto(out *RabbitmqClusterOverride
 		*out = new(ClientService)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterOverrideSpec.
@@ -277,6 +602,43 @@ func (in *RabbitmqClusterOverrideSpec) DeepCopy() *RabbitmqClusterOverrideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterOverview) DeepCopyInto(out *RabbitmqClusterOverview) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterOverview.
+func (in *RabbitmqClusterOverview) DeepCopy() *RabbitmqClusterOverview {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterOverview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterPerPodServiceSpec) DeepCopyInto(out *RabbitmqClusterPerPodServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterPerPodServiceSpec.
+func (in *RabbitmqClusterPerPodServiceSpec) DeepCopy() *RabbitmqClusterPerPodServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterPerPodServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterPersistenceSpec) DeepCopyInto(out *RabbitmqClusterPersistenceSpec) {
 	*out = *in
@@ -290,6 +652,28 @@ func (in *RabbitmqClusterPersistenceSpec) DeepCopyInto(out *RabbitmqClusterPersi
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(PersistentVolumeClaimRetentionPolicy)
+		**out = **in
+	}
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		*out = new(RabbitmqSnapshotReference)
+		**out = **in
+	}
+	if in.AdditionalVolumes != nil {
+		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
+		*out = make([]RabbitmqClusterAdditionalVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterPersistenceSpec.
@@ -302,6 +686,136 @@ func (in *RabbitmqClusterPersistenceSpec) DeepCopy() *RabbitmqClusterPersistence
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterPlacementSpec) DeepCopyInto(out *RabbitmqClusterPlacementSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterPlacementSpec.
+func (in *RabbitmqClusterPlacementSpec) DeepCopy() *RabbitmqClusterPlacementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterPlacementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterPreStopSpec) DeepCopyInto(out *RabbitmqClusterPreStopSpec) {
+	*out = *in
+	if in.AwaitQuorumPlusOne != nil {
+		in, out := &in.AwaitQuorumPlusOne, &out.AwaitQuorumPlusOne
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AwaitSynchronizedMirror != nil {
+		in, out := &in.AwaitSynchronizedMirror, &out.AwaitSynchronizedMirror
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterPreStopSpec.
+func (in *RabbitmqClusterPreStopSpec) DeepCopy() *RabbitmqClusterPreStopSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterPreStopSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterProbeSpec) DeepCopyInto(out *RabbitmqClusterProbeSpec) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterProbeSpec.
+func (in *RabbitmqClusterProbeSpec) DeepCopy() *RabbitmqClusterProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterProbesSpec) DeepCopyInto(out *RabbitmqClusterProbesSpec) {
+	*out = *in
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(RabbitmqClusterProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(RabbitmqClusterProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(RabbitmqClusterProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterProbesSpec.
+func (in *RabbitmqClusterProbesSpec) DeepCopy() *RabbitmqClusterProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterReference) DeepCopyInto(out *RabbitmqClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterReference.
+func (in *RabbitmqClusterReference) DeepCopy() *RabbitmqClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterSecretReference) DeepCopyInto(out *RabbitmqClusterSecretReference) {
 	*out = *in
@@ -370,6 +884,18 @@ func (in *RabbitmqClusterSpec) DeepCopyInto(out *RabbitmqClusterSpec) {
 		**out = **in
 	}
 	in.Service.DeepCopyInto(&out.Service)
+	if in.AdditionalServices != nil {
+		in, out := &in.AdditionalServices, &out.AdditionalServices
+		*out = make([]RabbitmqClusterAdditionalServiceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PerPodService != nil {
+		in, out := &in.PerPodService, &out.PerPodService
+		*out = new(RabbitmqClusterPerPodServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Persistence.DeepCopyInto(&out.Persistence)
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
@@ -381,6 +907,11 @@ func (in *RabbitmqClusterSpec) DeepCopyInto(out *RabbitmqClusterSpec) {
 		*out = new(v1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(RabbitmqClusterPlacementSpec)
+		**out = **in
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -391,6 +922,36 @@ func (in *RabbitmqClusterSpec) DeepCopyInto(out *RabbitmqClusterSpec) {
 	in.Rabbitmq.DeepCopyInto(&out.Rabbitmq)
 	out.TLS = in.TLS
 	in.Override.DeepCopyInto(&out.Override)
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(RabbitmqClusterMonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Management != nil {
+		in, out := &in.Management, &out.Management
+		*out = new(RabbitmqClusterManagementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(RabbitmqClusterNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PreStop != nil {
+		in, out := &in.PreStop, &out.PreStop
+		*out = new(RabbitmqClusterPreStopSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(RabbitmqClusterProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterSpec.
@@ -406,6 +967,10 @@ func (in *RabbitmqClusterSpec) DeepCopy() *RabbitmqClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterStatus) DeepCopyInto(out *RabbitmqClusterStatus) {
 	*out = *in
+	if in.FirstRunningTime != nil {
+		in, out := &in.FirstRunningTime, &out.FirstRunningTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]status.RabbitmqClusterCondition, len(*in))
@@ -418,6 +983,28 @@ func (in *RabbitmqClusterStatus) DeepCopyInto(out *RabbitmqClusterStatus) {
 		*out = new(RabbitmqClusterAdmin)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]RabbitmqClusterEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Overview != nil {
+		in, out := &in.Overview, &out.Overview
+		*out = new(RabbitmqClusterOverview)
+		**out = **in
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]RabbitmqNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.StorageMigration != nil {
+		in, out := &in.StorageMigration, &out.StorageMigration
+		*out = new(RabbitmqClusterStorageMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterStatus.
@@ -430,6 +1017,171 @@ func (in *RabbitmqClusterStatus) DeepCopy() *RabbitmqClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterStorageMigrationStatus) DeepCopyInto(out *RabbitmqClusterStorageMigrationStatus) {
+	*out = *in
+	if in.MigratedNodes != nil {
+		in, out := &in.MigratedNodes, &out.MigratedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterStorageMigrationStatus.
+func (in *RabbitmqClusterStorageMigrationStatus) DeepCopy() *RabbitmqClusterStorageMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterStorageMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqNodeStatus) DeepCopyInto(out *RabbitmqNodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqNodeStatus.
+func (in *RabbitmqNodeStatus) DeepCopy() *RabbitmqNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSnapshot) DeepCopyInto(out *RabbitmqSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSnapshot.
+func (in *RabbitmqSnapshot) DeepCopy() *RabbitmqSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSnapshotList) DeepCopyInto(out *RabbitmqSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RabbitmqSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSnapshotList.
+func (in *RabbitmqSnapshotList) DeepCopy() *RabbitmqSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSnapshotReference) DeepCopyInto(out *RabbitmqSnapshotReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSnapshotReference.
+func (in *RabbitmqSnapshotReference) DeepCopy() *RabbitmqSnapshotReference {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSnapshotReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSnapshotSpec) DeepCopyInto(out *RabbitmqSnapshotSpec) {
+	*out = *in
+	out.RabbitmqClusterReference = in.RabbitmqClusterReference
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSnapshotSpec.
+func (in *RabbitmqSnapshotSpec) DeepCopy() *RabbitmqSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSnapshotStatus) DeepCopyInto(out *RabbitmqSnapshotStatus) {
+	*out = *in
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = make([]RabbitmqSnapshotVolume, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSnapshotStatus.
+func (in *RabbitmqSnapshotStatus) DeepCopy() *RabbitmqSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSnapshotVolume) DeepCopyInto(out *RabbitmqSnapshotVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSnapshotVolume.
+func (in *RabbitmqSnapshotVolume) DeepCopy() *RabbitmqSnapshotVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSnapshotVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSet) DeepCopyInto(out *StatefulSet) {
 	*out = *in
@@ -0,0 +1,80 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+
+// setup-container prepares the configuration of a RabbitMQ node. It is shipped in the operator image,
+// and runs as the init container of the RabbitMQ Pods.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/rabbitmq/cluster-operator/internal/setup"
+)
+
+func main() {
+	var (
+		uid, gid               int
+		terminationMessagePath string
+	)
+	config := setup.DefaultConfig()
+	config.Warnings = os.Stderr
+	flag.IntVar(&uid, "uid", -1, "User to own the copied files. The files are owned by the user running the setup when unset.")
+	flag.IntVar(&gid, "gid", -1, "Group to own the copied files and the data directory. The group is left unchanged when unset.")
+	flag.StringVar(&config.StreamAdvertisedDomain, "stream-advertised-domain", "", "Domain the stream plugin advertises the node under, after its hostname.")
+	flag.BoolVar(&config.StreamAdvertisedTLS, "stream-advertised-tls", false, "Advertise the TLS port of the stream plugin.")
+	flag.StringVar(&config.NodeDomain, "node-domain", "", "Domain of the node name after its hostname.")
+	flag.StringVar(&config.MessageStoreDir, "message-store-dir", "", "Volume to link the classic message store of the node to. Requires --node-domain.")
+	flag.StringVar(&config.CommunityPluginsDir, "community-plugins-dir", "", "Volume the community plugins are downloaded to.")
+	flag.Var((*communityPlugins)(&config.CommunityPlugins), "community-plugin", "Community plugin to download, as <file>,<sha256>,<url>. Can be repeated.")
+	flag.StringVar(&terminationMessagePath, "termination-message-path", "/dev/termination-log", "File the error is written to, for Kubernetes to report it in the status of the Pod.")
+	flag.Parse()
+
+	if uid >= 0 {
+		config.UID = &uid
+	}
+	if gid >= 0 {
+		config.GID = &gid
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		fail(terminationMessagePath, fmt.Errorf("failed to get the hostname of the Pod: %w", err))
+	}
+	config.Hostname = hostname
+
+	if err := setup.Run(config); err != nil {
+		fail(terminationMessagePath, err)
+	}
+}
+
+// communityPlugins parses the repeated --community-plugin flag
+type communityPlugins []setup.CommunityPlugin
+
+func (plugins *communityPlugins) String() string {
+	return fmt.Sprint(*plugins)
+}
+
+func (plugins *communityPlugins) Set(value string) error {
+	fields := strings.SplitN(value, ",", 3)
+	if len(fields) != 3 {
+		return fmt.Errorf("expected <file>,<sha256>,<url>, got %q", value)
+	}
+	*plugins = append(*plugins, setup.CommunityPlugin{File: fields[0], SHA256: fields[1], URL: fields[2]})
+	return nil
+}
+
+func fail(terminationMessagePath string, err error) {
+	fmt.Fprintf(os.Stderr, "setup of the RabbitMQ node failed: %v\n", err)
+	// best effort: the log of the container is reported instead when the message cannot be written
+	_ = ioutil.WriteFile(terminationMessagePath, []byte(err.Error()), 0644)
+	os.Exit(1)
+}
@@ -0,0 +1,90 @@
+/*
+RabbitMQ Cluster Operator
+
+Copyright 2020 VMware, Inc. All Rights Reserved.
+
+This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+
+This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// setClusterPhase - helper function that moves status.clusterStatus to its next phase
+// it only mutates the status; callers are responsible for writing it
+func (r *RabbitmqClusterReconciler) setClusterPhase(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, childResources []runtime.Object) {
+	observation := status.PhaseObservation{
+		Deleting:                   !rmq.ObjectMeta.DeletionTimestamp.IsZero(),
+		Healthy:                    true,
+		StorageMigrationInProgress: rmq.Status.StorageMigration != nil,
+		HasBeenRunning:             rmq.Status.FirstRunningTime != nil,
+	}
+	if rmq.Spec.Replicas != nil {
+		observation.DesiredReplicas = *rmq.Spec.Replicas
+	}
+
+	for _, condition := range rmq.Status.Conditions {
+		switch condition.Type {
+		case status.ReconcileSuccess:
+			observation.ReconcileFailed = condition.Status == corev1.ConditionFalse
+		case status.ClusterAvailable:
+			observation.Healthy = observation.Healthy && condition.Status == corev1.ConditionTrue
+		case status.NoNetworkPartitions, status.NoResourceAlarms:
+			observation.Healthy = observation.Healthy && condition.Status != corev1.ConditionFalse
+		}
+	}
+
+	for _, res := range childResources {
+		if sts, ok := res.(*appsv1.StatefulSet); ok && sts != nil {
+			observation.StatefulSetExists = true
+			observation.CurrentReplicas = sts.Status.Replicas
+			observation.ReadyReplicas = sts.Status.ReadyReplicas
+			observation.RolloutInProgress = sts.Status.ObservedGeneration < sts.Generation ||
+				sts.Status.CurrentRevision != sts.Status.UpdateRevision ||
+				sts.Status.UpdatedReplicas < observation.DesiredReplicas
+			if observation.RolloutInProgress {
+				observation.ImageChanging = r.podImageChanging(ctx, rmq)
+			}
+		}
+	}
+
+	phase := status.NextPhase(status.ClusterPhase(rmq.Status.ClusterStatus), observation)
+	rmq.Status.ClusterStatus = string(phase)
+	if phase == status.PhaseRunning && rmq.Status.FirstRunningTime == nil {
+		now := metav1.Now()
+		rmq.Status.FirstRunningTime = &now
+	}
+}
+
+// podImageChanging - helper function that checks if any RabbitMQ container runs an image other than the desired one
+func (r *RabbitmqClusterReconciler) podImageChanging(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) bool {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.InNamespace(rmq.Namespace), client.MatchingLabels(metadata.LabelSelector(rmq.Name))); err != nil {
+		r.Log.Error(err, "Failed to list RabbitmqCluster Pods",
+			"namespace", rmq.Namespace,
+			"name", rmq.Name)
+		return false
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			if container.Name == "rabbitmq" && container.Image != rmq.Spec.Image {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+/*
+RabbitMQ Cluster Operator
+
+Copyright 2020 VMware, Inc. All Rights Reserved.
+
+This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+
+This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// applyDeletionRetentionPolicy - helper function that deletes or keeps the PersistentVolumeClaims of a deleted RabbitmqCluster
+// retained claims are released from the RabbitmqCluster, so that the garbage collector does not delete them with it
+func (r *RabbitmqClusterReconciler) applyDeletionRetentionPolicy(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	pvcs, err := r.controlledPVCs(ctx, rmq)
+	if err != nil {
+		return err
+	}
+	if len(pvcs) == 0 {
+		return nil
+	}
+
+	var names []string
+	if rmq.PVCRetentionWhenDeleted() == rabbitmqv1beta1.RetainPersistentVolumeClaimRetentionPolicyType {
+		for _, pvc := range pvcs {
+			var ownerReferences []metav1.OwnerReference
+			for _, ref := range pvc.OwnerReferences {
+				if ref.UID != rmq.UID {
+					ownerReferences = append(ownerReferences, ref)
+				}
+			}
+			pvc.OwnerReferences = ownerReferences
+			if err := r.Update(ctx, pvc); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("cannot release PersistentVolumeClaim %s: %w", pvc.Name, err)
+			}
+			names = append(names, pvc.Name)
+		}
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "RetainedPersistentVolumeClaims",
+			fmt.Sprintf("Kept PersistentVolumeClaims %s", strings.Join(names, ", ")))
+		return nil
+	}
+
+	for _, pvc := range pvcs {
+		if err := r.Delete(ctx, pvc); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("cannot delete PersistentVolumeClaim %s: %w", pvc.Name, err)
+		}
+		names = append(names, pvc.Name)
+	}
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "DeletedPersistentVolumeClaims",
+		fmt.Sprintf("Removed PersistentVolumeClaims %s", strings.Join(names, ", ")))
+	return nil
+}
+
+// applyScaleDownRetentionPolicy - helper function that deletes the PersistentVolumeClaims of Pods removed by a scale down
+// when spec.persistence.retentionPolicy.whenScaled is Delete; claims are only deleted once their Pod is gone
+// previousReplicas are the replicas of the StatefulSet before this reconciliation, used to report retained claims once
+func (r *RabbitmqClusterReconciler) applyScaleDownRetentionPolicy(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, previousReplicas int32) error {
+	if rmq.Spec.Replicas == nil {
+		return nil
+	}
+	if rmq.PVCRetentionWhenScaled() == rabbitmqv1beta1.RetainPersistentVolumeClaimRetentionPolicyType {
+		if previousReplicas > *rmq.Spec.Replicas {
+			return r.recordRetainedOnScaleDown(ctx, rmq, previousReplicas)
+		}
+		return nil
+	}
+
+	pvcs, err := r.controlledPVCs(ctx, rmq)
+	if err != nil {
+		return err
+	}
+
+	stsName := rmq.ChildResourceName("server")
+	var names []string
+	for _, pvc := range pvcs {
+		ordinal, ok := statefulSetOrdinal(pvc.Name, stsName)
+		if !ok || ordinal < *rmq.Spec.Replicas {
+			continue
+		}
+
+		pod := &corev1.Pod{}
+		podName := fmt.Sprintf("%s-%d", stsName, ordinal)
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: podName}, pod); !errors.IsNotFound(err) {
+			// the Pod is still terminating, or could not be checked
+			continue
+		}
+
+		if err := r.Delete(ctx, pvc); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("cannot delete PersistentVolumeClaim %s: %w", pvc.Name, err)
+		}
+		names = append(names, pvc.Name)
+	}
+
+	if len(names) > 0 {
+		r.Log.Info("Deleted PersistentVolumeClaims of removed nodes",
+			"namespace", rmq.Namespace,
+			"name", rmq.Name,
+			"persistentVolumeClaims", names)
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "DeletedPersistentVolumeClaims",
+			fmt.Sprintf("Removed PersistentVolumeClaims of scaled down nodes %s", strings.Join(names, ", ")))
+	}
+	return nil
+}
+
+// recordRetainedOnScaleDown - helper function that reports the PersistentVolumeClaims kept for the Pods removed by a scale down
+func (r *RabbitmqClusterReconciler) recordRetainedOnScaleDown(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, previousReplicas int32) error {
+	pvcs, err := r.controlledPVCs(ctx, rmq)
+	if err != nil {
+		return err
+	}
+
+	stsName := rmq.ChildResourceName("server")
+	var names []string
+	for _, pvc := range pvcs {
+		if ordinal, ok := statefulSetOrdinal(pvc.Name, stsName); ok && ordinal >= *rmq.Spec.Replicas && ordinal < previousReplicas {
+			names = append(names, pvc.Name)
+		}
+	}
+
+	if len(names) > 0 {
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "RetainedPersistentVolumeClaims",
+			fmt.Sprintf("Kept PersistentVolumeClaims of scaled down nodes %s", strings.Join(names, ", ")))
+	}
+	return nil
+}
+
+// controlledPVCs - helper function that lists the PersistentVolumeClaims created from the volume claim templates of the RabbitmqCluster
+func (r *RabbitmqClusterReconciler) controlledPVCs(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) ([]*corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcList, client.InNamespace(rmq.Namespace), client.MatchingLabels(metadata.LabelSelector(rmq.Name))); err != nil {
+		return nil, err
+	}
+
+	var pvcs []*corev1.PersistentVolumeClaim
+	for i := range pvcList.Items {
+		if metav1.IsControlledBy(&pvcList.Items[i], rmq) {
+			pvcs = append(pvcs, &pvcList.Items[i])
+		}
+	}
+	return pvcs, nil
+}
+
+// statefulSetOrdinal returns the ordinal of the Pod a PersistentVolumeClaim named <template>-<StatefulSet>-<ordinal> was created for
+func statefulSetOrdinal(pvcName, stsName string) (int32, bool) {
+	i := strings.LastIndex(pvcName, "-")
+	if i < 0 || !strings.HasSuffix(pvcName[:i], "-"+stsName) {
+		return 0, false
+	}
+	ordinal, err := strconv.ParseInt(pvcName[i+1:], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(ordinal), true
+}
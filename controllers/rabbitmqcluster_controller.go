@@ -14,9 +14,14 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -27,9 +32,13 @@ import (
 
 	"k8s.io/apimachinery/pkg/labels"
 
+	"github.com/rabbitmq/cluster-operator/internal/management"
+	"github.com/rabbitmq/cluster-operator/internal/metrics"
 	"github.com/rabbitmq/cluster-operator/internal/resource"
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/record"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -38,6 +47,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/go-logr/logr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -46,6 +56,9 @@ import (
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -58,6 +71,9 @@ const (
 	ownerKey          = ".metadata.controller"
 	ownerKind         = "RabbitmqCluster"
 	deletionFinalizer = "deletion.finalizers.rabbitmqclusters.rabbitmq.com"
+	// how often the reconciliation is requeued to refresh the status from the management API, once all replicas are ready;
+	// until then the reconciliation is requeued to enable plugins
+	statusRefreshInterval = 30 * time.Second
 )
 
 // RabbitmqClusterReconciler reconciles a RabbitmqCluster object
@@ -69,13 +85,18 @@ type RabbitmqClusterReconciler struct {
 	Recorder      record.EventRecorder
 	ClusterConfig *rest.Config
 	Clientset     *kubernetes.Clientset
+	// Image of the setup-container of the RabbitMQ Pods
+	SetupContainerImage string
+	// Labels of the operator Pod, allowed to reach the management API through the NetworkPolicies
+	OperatorPodLabels map[string]string
 }
 
 // the rbac rule requires an empty row at the end to render
 // +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
-// +kubebuilder:rbac:groups="",resources=pods,verbs=update;get;list;watch
-// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=pods,verbs=update;get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;watch;list
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update
@@ -86,9 +107,32 @@ type RabbitmqClusterReconciler struct {
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=rolebindings,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;networkpolicies,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors;servicemonitors;prometheusrules,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=list
+
+func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	if r.observable(req.NamespacedName) {
+		metrics.ObserveReconcile(req.Namespace, req.Name, time.Since(start), err)
+	}
+	return result, err
+}
+
+// observable - helper function that checks whether the reconciliation of a RabbitmqCluster should be recorded in the metrics.
+// The series of deleted RabbitmqClusters, and of those being deleted, are removed by metrics.ForgetCluster and must not be recreated
+func (r *RabbitmqClusterReconciler) observable(namespacedName types.NamespacedName) bool {
+	rabbitmqCluster, err := r.getRabbitmqCluster(context.Background(), namespacedName)
+	if err != nil {
+		return !errors.IsNotFound(err)
+	}
+	return rabbitmqCluster.ObjectMeta.DeletionTimestamp.IsZero()
+}
 
-func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
+func (r *RabbitmqClusterReconciler) reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	logger := r.Log
 
 	fetchedRabbitmqCluster, err := r.getRabbitmqCluster(ctx, req.NamespacedName)
@@ -115,6 +159,15 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		logger.Info("Deleting RabbitmqCluster",
 			"namespace", rabbitmqCluster.Namespace,
 			"name", rabbitmqCluster.Name)
+		if rabbitmqCluster.Status.ClusterStatus != string(status.PhaseDeleting) {
+			rabbitmqCluster.Status.ClusterStatus = string(status.PhaseDeleting)
+			if err := r.Status().Update(ctx, rabbitmqCluster); client.IgnoreNotFound(err) != nil {
+				r.Log.Error(err, "Error trying to Update Custom Resource status",
+					"namespace", rabbitmqCluster.Namespace,
+					"name", rabbitmqCluster.Name)
+			}
+		}
+		metrics.ForgetCluster(rabbitmqCluster)
 		// Stop reconciliation as the item is being deleted
 		return ctrl.Result{}, r.prepareForDeletion(ctx, rabbitmqCluster)
 	}
@@ -122,6 +175,7 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 	// TLS: check if specified, and if secret exists
 	if rabbitmqCluster.TLSEnabled() {
 		if result, err := r.checkTLSSecrets(ctx, rabbitmqCluster); err != nil {
+			metrics.TLSSecretValidationFailures.WithLabelValues(rabbitmqCluster.Namespace, rabbitmqCluster.Name).Inc()
 			return result, err
 		}
 	}
@@ -136,15 +190,25 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		return ctrl.Result{}, err
 	}
 
-	oldConditions := make([]status.RabbitmqClusterCondition, len(rabbitmqCluster.Status.Conditions))
-	copy(oldConditions, rabbitmqCluster.Status.Conditions)
-	rabbitmqCluster.Status.SetConditions(childResources)
-
-	if !reflect.DeepEqual(rabbitmqCluster.Status.Conditions, oldConditions) {
-		if err = r.Status().Update(ctx, rabbitmqCluster); err != nil {
-			return ctrl.Result{}, err
+	// the status is written once, when the reconciliation returns
+	oldStatus := rabbitmqCluster.Status.DeepCopy()
+	defer func() {
+		status.KeepTransitionTimes(rabbitmqCluster.Status.Conditions, oldStatus.Conditions)
+		if reflect.DeepEqual(*oldStatus, rabbitmqCluster.Status) {
+			return
 		}
-	}
+		if writerErr := r.Status().Update(ctx, rabbitmqCluster); writerErr != nil {
+			r.Log.Error(writerErr, "Error trying to Update Custom Resource status",
+				"namespace", rabbitmqCluster.Namespace,
+				"name", rabbitmqCluster.Name)
+			if err == nil {
+				err = writerErr
+			}
+		}
+	}()
+
+	rabbitmqCluster.Status.SetConditions(childResources)
+	r.setClusterPhase(ctx, rabbitmqCluster, childResources)
 
 	instanceSpec, err := json.Marshal(rabbitmqCluster.Spec)
 	if err != nil {
@@ -157,8 +221,12 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		"spec", string(instanceSpec))
 
 	resourceBuilder := resource.RabbitmqResourceBuilder{
-		Instance: rabbitmqCluster,
-		Scheme:   r.Scheme,
+		Instance:                rabbitmqCluster,
+		Scheme:                  r.Scheme,
+		OperatorPodLabels:       r.OperatorPodLabels,
+		MonitoringCRDsInstalled: r.monitoringCRDsInstalled(ctx, rabbitmqCluster),
+		GatewayAPICRDsInstalled: r.gatewayAPICRDsInstalled(ctx, rabbitmqCluster),
+		SetupContainerImage:     r.SetupContainerImage,
 	}
 
 	builders, err := resourceBuilder.ResourceBuilders()
@@ -166,6 +234,15 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		return ctrl.Result{}, err
 	}
 
+	if waiting, err := r.restorePersistentVolumeClaims(ctx, rabbitmqCluster, &resourceBuilder); err != nil || waiting {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+
+	if recreating, err := r.startStorageMigration(ctx, rabbitmqCluster); err != nil || recreating {
+		// the StatefulSet is created again with the new StorageClass once it is deleted
+		return ctrl.Result{RequeueAfter: time.Second * 2}, err
+	}
+
 	for _, builder := range builders {
 		resource, err := builder.Build()
 		if err != nil {
@@ -180,7 +257,7 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		var operationResult controllerutil.OperationResult
 		err = clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
 			var apiError error
-			operationResult, apiError = controllerutil.CreateOrUpdate(ctx, r, resource, func() error {
+			operationResult, apiError = controllerutil.CreateOrUpdate(ctx, r.Client, resource.(client.Object), func() error {
 				return builder.Update(resource)
 			})
 
@@ -189,11 +266,8 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		r.logAndRecordOperationResult(rabbitmqCluster, resource, operationResult, err)
 		if err != nil {
 			rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "Error", err.Error())
-			if writerErr := r.Status().Update(ctx, rabbitmqCluster); writerErr != nil {
-				r.Log.Error(writerErr, "Error trying to Update ReconcileSuccess condition state",
-					"namespace", rabbitmqCluster.Namespace,
-					"name", rabbitmqCluster.Name)
-			}
+			rabbitmqCluster.Status.SetObservedGeneration(rabbitmqCluster.Generation)
+			r.setClusterPhase(ctx, rabbitmqCluster, childResources)
 
 			return ctrl.Result{}, err
 		}
@@ -201,19 +275,29 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		r.restartStatefulSetIfNeeded(ctx, builder, operationResult, rabbitmqCluster)
 	}
 
+	if err := r.deleteStaleResources(ctx, rabbitmqCluster, builders); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.applyScaleDownRetentionPolicy(ctx, rabbitmqCluster, statefulSetReplicas(childResources)); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Set ReconcileSuccess to true here because all CRUD operations to Kube API related
 	// to child resources returned no error
 	rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionTrue, "Success", "Created or Updated all child resources")
-	if writerErr := r.Status().Update(ctx, rabbitmqCluster); writerErr != nil {
-		r.Log.Error(writerErr, "Error trying to Update Custom Resource status",
-			"namespace", rabbitmqCluster.Namespace,
-			"name", rabbitmqCluster.Name)
-	}
+	rabbitmqCluster.Status.SetObservedGeneration(rabbitmqCluster.Generation)
+	r.setClusterPhase(ctx, rabbitmqCluster, childResources)
+	r.setAdminStatus(rabbitmqCluster)
 
-	if err := r.setAdminStatus(ctx, rabbitmqCluster); err != nil {
+	if err := r.setEndpointsStatus(ctx, rabbitmqCluster); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	if result, err := r.migrateStorage(ctx, rabbitmqCluster); err != nil || !result.IsZero() {
+		return result, err
+	}
+
 	if ok, err := r.allReplicasReady(ctx, rabbitmqCluster); !ok {
 		// only enable plugins when all pods of the StatefulSet become ready
 		// requeue request after 10 seconds without error
@@ -227,11 +311,16 @@ func (r *RabbitmqClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		return ctrl.Result{}, err
 	}
 
+	if err := r.setManagementStatus(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	logger.Info("Finished reconciling RabbitmqCluster",
 		"namespace", rabbitmqCluster.Namespace,
 		"name", rabbitmqCluster.Name)
 
-	return ctrl.Result{}, nil
+	// all replicas are ready: only the status from the management API needs refreshing
+	return ctrl.Result{RequeueAfter: statusRefreshInterval}, nil
 }
 
 func (r *RabbitmqClusterReconciler) checkTLSSecrets(ctx context.Context, rabbitmqCluster *rabbitmqv1beta1.RabbitmqCluster) (ctrl.Result, error) {
@@ -255,6 +344,7 @@ func (r *RabbitmqClusterReconciler) checkTLSSecrets(ctx context.Context, rabbitm
 
 		return ctrl.Result{}, errors.NewBadRequest("The TLS secret must have the fields tls.crt and tls.key")
 	}
+	r.recordCertificateExpiry(rabbitmqCluster, secretName, secret.Data["tls.crt"])
 
 	// Mutual TLS: check if CA certificate is stored in a separate secret
 	if rabbitmqCluster.MutualTLSEnabled() {
@@ -282,7 +372,65 @@ func (r *RabbitmqClusterReconciler) checkTLSSecrets(ctx context.Context, rabbitm
 	return ctrl.Result{}, nil
 }
 
-func (r *RabbitmqClusterReconciler) setAdminStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+// recordCertificateExpiry - helper function that exports the expiry time of the first certificate in a PEM bundle
+// certificates that cannot be parsed are ignored; RabbitMQ itself reports them on startup
+func (r *RabbitmqClusterReconciler) recordCertificateExpiry(rmq *rabbitmqv1beta1.RabbitmqCluster, secretName string, certPEM []byte) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return
+	}
+	metrics.CertificateExpiry.WithLabelValues(rmq.Namespace, rmq.Name, secretName).Set(float64(cert.NotAfter.Unix()))
+}
+
+// monitoringCRDsInstalled - helper function that checks whether the Prometheus Operator CRDs requested by spec.monitoring exist
+func (r *RabbitmqClusterReconciler) monitoringCRDsInstalled(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) bool {
+	if !rmq.MonitoringEnabled() {
+		return false
+	}
+
+	kind := resource.PodMonitorKind
+	if rmq.ServiceMonitorEnabled() {
+		kind = resource.ServiceMonitorKind
+	}
+	if !kindInstalled(ctx, r.Client, rmq.Namespace, resource.MonitoringGroupVersion.WithKind(kind)) {
+		msg := fmt.Sprintf("spec.monitoring is set but the %s CRD of the Prometheus Operator is not installed", kind)
+		r.Log.Info(msg, "namespace", rmq.Namespace, "name", rmq.Name)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "MonitoringUnavailable", msg)
+		return false
+	}
+	return true
+}
+
+// gatewayAPICRDsInstalled - helper function that checks whether the Gateway API HTTPRoute CRD requested by spec.management.ingress exists
+func (r *RabbitmqClusterReconciler) gatewayAPICRDsInstalled(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) bool {
+	if !rmq.ManagementHTTPRouteEnabled() {
+		return false
+	}
+
+	if !kindInstalled(ctx, r.Client, rmq.Namespace, resource.GatewayGroupVersion.WithKind(resource.HTTPRouteKind)) {
+		msg := fmt.Sprintf("spec.management.ingress.kind is %s but the %s CRD of the Gateway API is not installed", resource.HTTPRouteKind, resource.HTTPRouteKind)
+		r.Log.Info(msg, "namespace", rmq.Namespace, "name", rmq.Name)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "ManagementIngressUnavailable", msg)
+		return false
+	}
+	return true
+}
+
+// kindInstalled - helper function that checks whether the API server serves the given kind, by listing it
+func kindInstalled(ctx context.Context, c client.Client, namespace string, gvk schema.GroupVersionKind) bool {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	err := c.List(ctx, list, client.InNamespace(namespace), client.Limit(1))
+	return !meta.IsNoMatchError(err)
+}
+
+// setAdminStatus - helper function that publishes the Service and Secret to connect to the RabbitmqCluster as its default user
+// it only mutates the status; callers are responsible for writing it
+func (r *RabbitmqClusterReconciler) setAdminStatus(rmq *rabbitmqv1beta1.RabbitmqCluster) {
 
 	adminStatus := &rabbitmqv1beta1.RabbitmqClusterAdmin{}
 
@@ -302,16 +450,165 @@ func (r *RabbitmqClusterReconciler) setAdminStatus(ctx context.Context, rmq *rab
 	}
 	adminStatus.SecretReference = secretRef
 
-	if !reflect.DeepEqual(rmq.Status.Admin, adminStatus) {
-		rmq.Status.Admin = adminStatus
-		if err := r.Status().Update(ctx, rmq); err != nil {
-			return err
+	rmq.Status.Admin = adminStatus
+}
+
+// setEndpointsStatus - helper function that publishes the addresses of the client Service and the additional Services
+// the Services are owned by the RabbitmqCluster, so NodePort allocations and load balancer ingresses trigger a reconciliation
+// it only mutates the status; callers are responsible for writing it
+func (r *RabbitmqClusterReconciler) setEndpointsStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	names := []string{rmq.ChildResourceName("client")}
+	for _, service := range rmq.Spec.AdditionalServices {
+		names = append(names, rmq.ChildResourceName(service.Name))
+	}
+
+	var services []*corev1.Service
+	for _, name := range names {
+		service := &corev1.Service{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: name}, service); err != nil {
+			return client.IgnoreNotFound(err)
 		}
+		services = append(services, service)
 	}
 
+	rmq.Status.Endpoints = resource.Endpoints(services...)
 	return nil
 }
 
+// setManagementStatus - helper function that publishes the cluster overview, node states and node conditions reported by the management API
+// failing to reach the management API is logged but does not fail the reconciliation, the status is refreshed on the next requeue
+// it only mutates the status; callers are responsible for writing it
+func (r *RabbitmqClusterReconciler) setManagementStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	managementClient, err := r.managementClient(ctx, rmq)
+	if err != nil {
+		return err
+	}
+
+	overviewStatus := rmq.Status.Overview
+	if overview, err := managementClient.Overview(ctx); err != nil {
+		r.Log.Error(err, "Failed to query management API overview",
+			"namespace", rmq.Namespace,
+			"name", rmq.Name)
+	} else {
+		overviewStatus = &rabbitmqv1beta1.RabbitmqClusterOverview{
+			ClusterName:            overview.ClusterName,
+			RabbitmqVersion:        overview.RabbitmqVersion,
+			ErlangVersion:          overview.ErlangVersion,
+			Connections:            overview.ObjectTotals.Connections,
+			Queues:                 overview.ObjectTotals.Queues,
+			Messages:               overview.QueueTotals.Messages,
+			MessagesReady:          overview.QueueTotals.MessagesReady,
+			MessagesUnacknowledged: overview.QueueTotals.MessagesUnacknowledged,
+		}
+	}
+
+	nodesStatus := rmq.Status.Nodes
+	nodes, err := managementClient.Nodes(ctx)
+	if err != nil {
+		r.Log.Error(err, "Failed to query management API nodes",
+			"namespace", rmq.Namespace,
+			"name", rmq.Name)
+	} else {
+		nodesStatus = make([]rabbitmqv1beta1.RabbitmqNodeStatus, len(nodes))
+		for i, node := range nodes {
+			nodesStatus[i] = rabbitmqv1beta1.RabbitmqNodeStatus{
+				Name:          node.Name,
+				Running:       node.Running,
+				UptimeSeconds: node.Uptime / 1000,
+			}
+		}
+		sort.Slice(nodesStatus, func(i, j int) bool {
+			return nodesStatus[i].Name < nodesStatus[j].Name
+		})
+	}
+
+	oldConditions := make([]status.RabbitmqClusterCondition, len(rmq.Status.Conditions))
+	copy(oldConditions, rmq.Status.Conditions)
+	status.UpdateNodeConditions(rmq.Status.Conditions, nodes)
+	r.recordNodeConditionTransitions(rmq, oldConditions)
+
+	rmq.Status.Overview = overviewStatus
+	rmq.Status.Nodes = nodesStatus
+	return nil
+}
+
+// recordNodeConditionTransitions - helper function that records a Warning event when a network partition or resource alarm
+// is detected and a Normal event once it clears
+func (r *RabbitmqClusterReconciler) recordNodeConditionTransitions(rmq *rabbitmqv1beta1.RabbitmqCluster, oldConditions []status.RabbitmqClusterCondition) {
+	for _, condition := range rmq.Status.Conditions {
+		if condition.Type != status.NoNetworkPartitions && condition.Type != status.NoResourceAlarms {
+			continue
+		}
+
+		var oldStatus corev1.ConditionStatus
+		for _, oldCondition := range oldConditions {
+			if oldCondition.Type == condition.Type {
+				oldStatus = oldCondition.Status
+			}
+		}
+
+		if condition.Status == corev1.ConditionFalse && oldStatus != corev1.ConditionFalse {
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, condition.Reason, condition.Message)
+		}
+		if condition.Status == corev1.ConditionTrue && oldStatus == corev1.ConditionFalse {
+			r.Recorder.Event(rmq, corev1.EventTypeNormal, condition.Reason, fmt.Sprintf("%s condition is now true", condition.Type))
+		}
+	}
+}
+
+// managementClient - helper function that builds a management API client authenticated with the default user of the RabbitmqCluster
+// the scheme and port are those of the management port of the client Service
+func (r *RabbitmqClusterReconciler) managementClient(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (*management.Client, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rmq.ChildResourceName(resource.AdminSecretName), Namespace: rmq.Namespace}, secret); err != nil {
+		return nil, err
+	}
+
+	service := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rmq.ChildResourceName("client"), Namespace: rmq.Namespace}, service); err != nil {
+		return nil, err
+	}
+	baseURL, err := resource.ManagementURL(rmq, service)
+	if err != nil {
+		return nil, err
+	}
+
+	managementClient := management.NewClient(baseURL, string(secret.Data["username"]), string(secret.Data["password"]))
+	if strings.HasPrefix(baseURL, "https://") {
+		rootCAs, err := r.managementRootCAs(ctx, rmq)
+		if err != nil {
+			return nil, err
+		}
+		managementClient.HTTPClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs},
+		}
+	}
+	return managementClient, nil
+}
+
+// managementRootCAs - helper function that returns the system certificate authorities, extended with the CA certificate of spec.tls.caSecretName,
+// or the ca.crt of spec.tls.secretName, to verify the certificate of the management API
+func (r *RabbitmqClusterReconciler) managementRootCAs(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (*x509.CertPool, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rmq.TLSEnabled() {
+		return rootCAs, nil
+	}
+
+	secretName, key := rmq.Spec.TLS.SecretName, "ca.crt"
+	if rmq.MutualTLSEnabled() {
+		secretName, key = rmq.Spec.TLS.CaSecretName, rmq.Spec.TLS.CaCertName
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: rmq.Namespace}, secret); err != nil {
+		return nil, err
+	}
+	rootCAs.AppendCertsFromPEM(secret.Data[key])
+	return rootCAs, nil
+}
+
 // restartStatefulSetIfNeeded - helper function that annotates the StatefulSet PodTemplate with current timestamp
 // to trigger a restart of the all pods in the StatefulSet when builder requires StatefulSet to be updated
 func (r *RabbitmqClusterReconciler) restartStatefulSetIfNeeded(ctx context.Context, builder resource.ResourceBuilder, operationResult controllerutil.OperationResult, rmq *rabbitmqv1beta1.RabbitmqCluster) {
@@ -324,13 +621,15 @@ func (r *RabbitmqClusterReconciler) restartStatefulSetIfNeeded(ctx context.Conte
 			if sts.Spec.Template.ObjectMeta.Annotations == nil {
 				sts.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
 			}
-			sts.Spec.Template.ObjectMeta.Annotations["rabbitmq.com/restartAt"] = time.Now().Format(time.RFC3339)
+			sts.Spec.Template.ObjectMeta.Annotations[resource.RestartAtAnnotation] = time.Now().Format(time.RFC3339)
 			return r.Update(ctx, sts)
 		}); err != nil {
 			msg := fmt.Sprintf("Failed to restart StatefulSet %s of Namespace %s; rabbitmq.conf configuration may be outdated", rmq.ChildResourceName("server"), rmq.Namespace)
 			r.Log.Error(err, msg)
 			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedUpdate", msg)
+			return
 		}
+		metrics.RollingRestarts.WithLabelValues(rmq.Namespace, rmq.Name).Inc()
 		msg := fmt.Sprintf("Restarted StatefulSet %s of Namespace %s", rmq.ChildResourceName("server"), rmq.Namespace)
 		r.Log.Info(msg)
 		r.Recorder.Event(rmq, corev1.EventTypeNormal, "SuccessfulUpdate", msg)
@@ -355,7 +654,7 @@ func (r *RabbitmqClusterReconciler) allReplicasReady(ctx context.Context, rmq *r
 // enablePlugins - helper function to set the list of enabled plugins in a given RabbitmqCluster pods
 // `rabbitmq-plugins set` disables plugins that are not in the provided list
 func (r *RabbitmqClusterReconciler) enablePlugins(rmq *rabbitmqv1beta1.RabbitmqCluster) error {
-	plugins := resource.NewRabbitmqPlugins(rmq.Spec.Rabbitmq.AdditionalPlugins)
+	plugins := resource.NewRabbitmqPlugins(resource.EnabledPlugins(rmq))
 	for i := int32(0); i < *rmq.Spec.Replicas; i++ {
 		podName := fmt.Sprintf("%s-%d", rmq.ChildResourceName("server"), i)
 		rabbitCommand := fmt.Sprintf("rabbitmq-plugins set %s", plugins.AsString(" "))
@@ -363,7 +662,7 @@ func (r *RabbitmqClusterReconciler) enablePlugins(rmq *rabbitmqv1beta1.RabbitmqC
 		stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", rabbitCommand)
 
 		if err != nil {
-
+			metrics.PluginEnableFailures.WithLabelValues(rmq.Namespace, rmq.Name).Inc()
 			r.Log.Error(err, fmt.Sprintf(
 				"Failed to enable plugins on pod %s in namespace %s, running command %s with output: %s %s",
 				podName, rmq.Namespace, rabbitCommand, stdout, stderr))
@@ -379,7 +678,12 @@ func (r *RabbitmqClusterReconciler) enablePlugins(rmq *rabbitmqv1beta1.RabbitmqC
 }
 
 func (r *RabbitmqClusterReconciler) exec(namespace, podName, containerName string, command ...string) (string, string, error) {
-	request := r.Clientset.CoreV1().RESTClient().
+	return execInPod(r.ClusterConfig, r.Clientset, namespace, podName, containerName, command...)
+}
+
+// execInPod runs command in a container of a Pod, returning its stdout and stderr
+func execInPod(config *rest.Config, clientset *kubernetes.Clientset, namespace, podName, containerName string, command ...string) (string, string, error) {
+	request := clientset.CoreV1().RESTClient().
 		Post().
 		Resource("pods").
 		Name(podName).
@@ -393,7 +697,7 @@ func (r *RabbitmqClusterReconciler) exec(namespace, podName, containerName strin
 			Stdin:     false,
 		}, scheme.ParameterCodec)
 
-	exec, err := remotecommand.NewSPDYExecutor(r.ClusterConfig, "POST", request.URL())
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", request.URL())
 	if err != nil {
 		return "", "", err
 	}
@@ -473,6 +777,11 @@ func (r *RabbitmqClusterReconciler) prepareForDeletion(ctx context.Context, rabb
 			r.Log.Error(err, "RabbitmqCluster deletion")
 		}
 
+		if err := r.applyDeletionRetentionPolicy(ctx, rabbitmqCluster); err != nil {
+			r.Log.Error(err, "Failed to apply the PersistentVolumeClaim retention policy for deletion")
+			return err
+		}
+
 		if err := r.removeFinalizer(ctx, rabbitmqCluster); err != nil {
 			r.Log.Error(err, "Failed to remove finalizer for deletion")
 			return err
@@ -549,6 +858,16 @@ func (r *RabbitmqClusterReconciler) getChildResources(ctx context.Context, rmq r
 	return []runtime.Object{sts, endPoints}, nil
 }
 
+// statefulSetReplicas - helper function that returns the replicas of the StatefulSet among the child resources, or 0 when it does not exist
+func statefulSetReplicas(childResources []runtime.Object) int32 {
+	for _, res := range childResources {
+		if sts, ok := res.(*appsv1.StatefulSet); ok && sts != nil && sts.Spec.Replicas != nil {
+			return *sts.Spec.Replicas
+		}
+	}
+	return 0
+}
+
 func (r *RabbitmqClusterReconciler) getRabbitmqCluster(ctx context.Context, namespacedName types.NamespacedName) (*rabbitmqv1beta1.RabbitmqCluster, error) {
 	rabbitmqClusterInstance := &rabbitmqv1beta1.RabbitmqCluster{}
 	err := r.Get(ctx, namespacedName, rabbitmqClusterInstance)
@@ -556,12 +875,16 @@ func (r *RabbitmqClusterReconciler) getRabbitmqCluster(ctx context.Context, name
 }
 
 func (r *RabbitmqClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	for _, resource := range []runtime.Object{&appsv1.StatefulSet{}, &corev1.ConfigMap{}, &corev1.Service{}} {
+	for _, resource := range []client.Object{&appsv1.StatefulSet{}, &corev1.ConfigMap{}, &corev1.Service{}} {
 		if err := mgr.GetFieldIndexer().IndexField(context.Background(), resource, ownerKey, addResourceToIndex); err != nil {
 			return err
 		}
 	}
 
+	if err := metrics.RegisterManagedClustersCollector(mgr.GetClient()); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&rabbitmqv1beta1.RabbitmqCluster{}).
 		Owns(&appsv1.StatefulSet{}).
@@ -571,10 +894,13 @@ func (r *RabbitmqClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&corev1.Secret{}).
+		Owns(&policyv1beta1.PodDisruptionBudget{}).
+		Owns(&networkingv1beta1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
 		Complete(r)
 }
 
-func addResourceToIndex(rawObj runtime.Object) []string {
+func addResourceToIndex(rawObj client.Object) []string {
 	switch resourceObject := rawObj.(type) {
 	case *appsv1.StatefulSet:
 		owner := metav1.GetControllerOf(resourceObject)
@@ -13,6 +13,7 @@ package controllers_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -24,12 +25,19 @@ import (
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
@@ -46,12 +54,7 @@ var _ = Describe("RabbitmqclusterController", func() {
 		ctx                    = context.Background()
 		updateWithRetry        = func(cr *rabbitmqv1beta1.RabbitmqCluster, mutateFn func(r *rabbitmqv1beta1.RabbitmqCluster)) error {
 			return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-				objKey, err := runtimeClient.ObjectKeyFromObject(cr)
-				if err != nil {
-					return err
-				}
-
-				if err := client.Get(ctx, objKey, cr); err != nil {
+				if err := client.Get(ctx, runtimeClient.ObjectKeyFromObject(cr), cr); err != nil {
 					return err
 				}
 
@@ -194,6 +197,34 @@ var _ = Describe("RabbitmqclusterController", func() {
 				}, 5).Should(Equal("deletion.finalizers.rabbitmqclusters.rabbitmq.com"))
 			})
 
+			By("setting the cluster phase in the custom resource status", func() {
+				Eventually(func() string {
+					rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+					if err := client.Get(ctx, types.NamespacedName{Name: rabbitmqCluster.Name, Namespace: rabbitmqCluster.Namespace}, rmq); err != nil {
+						return ""
+					}
+					return rmq.Status.ClusterStatus
+				}, 5).Should(Equal(string(status.PhaseInitializing)))
+			})
+
+			By("setting the observed generation in the custom resource status", func() {
+				Eventually(func() int64 {
+					rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+					if err := client.Get(ctx, types.NamespacedName{Name: rabbitmqCluster.Name, Namespace: rabbitmqCluster.Namespace}, rmq); err != nil {
+						return -1
+					}
+					if rmq.Status.ObservedGeneration != rmq.Generation {
+						return -1
+					}
+					for _, condition := range rmq.Status.Conditions {
+						if condition.Type == status.Ready {
+							return condition.ObservedGeneration
+						}
+					}
+					return -1
+				}, 5).Should(BeNumerically(">", 0))
+			})
+
 			By("setting the admin secret details in the custom resource status", func() {
 				rmq := &rabbitmqv1beta1.RabbitmqCluster{}
 				secretRef := &rabbitmqv1beta1.RabbitmqClusterSecretReference{}
@@ -237,6 +268,21 @@ var _ = Describe("RabbitmqclusterController", func() {
 				Expect(serviceRef.Name).To(Equal(rmq.ChildResourceName("client")))
 				Expect(serviceRef.Namespace).To(Equal(rmq.Namespace))
 			})
+
+			By("setting the client endpoints in the custom resource status", func() {
+				rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+				Eventually(func() []rabbitmqv1beta1.RabbitmqClusterEndpoint {
+					if err := client.Get(ctx, types.NamespacedName{Name: rabbitmqCluster.Name, Namespace: rabbitmqCluster.Namespace}, rmq); err != nil {
+						return nil
+					}
+					return rmq.Status.Endpoints
+				}, 5).Should(ContainElement(rabbitmqv1beta1.RabbitmqClusterEndpoint{
+					Protocol: "amqp",
+					Service:  rabbitmqCluster.ChildResourceName("client"),
+					Host:     rabbitmqCluster.ChildResourceName("client") + "." + rabbitmqCluster.Namespace + ".svc",
+					Port:     5672,
+				}))
+			})
 		})
 	})
 
@@ -520,6 +566,340 @@ var _ = Describe("RabbitmqclusterController", func() {
 		})
 	})
 
+	Context("PodDisruptionBudget", func() {
+		var three int32 = 3
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+		})
+
+		It("creates a PodDisruptionBudget for a multi-node cluster", func() {
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-pdb",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: &three,
+				},
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+
+			pdbName := rabbitmqCluster.ChildResourceName("server")
+			Eventually(func() error {
+				_, err := clientSet.PolicyV1beta1().PodDisruptionBudgets(rabbitmqCluster.Namespace).Get(ctx, pdbName, metav1.GetOptions{})
+				return err
+			}, 5).Should(Succeed())
+			pdb, err := clientSet.PolicyV1beta1().PodDisruptionBudgets(rabbitmqCluster.Namespace).Get(ctx, pdbName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+			Expect(pdb.OwnerReferences[0].Name).To(Equal(rabbitmqCluster.Name))
+		})
+	})
+
+	Context("Per-pod Services", func() {
+		var three int32 = 3
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+		})
+
+		It("creates a Service per node and deletes the Services of removed nodes", func() {
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-per-pod",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas:      &three,
+					PerPodService: &rabbitmqv1beta1.RabbitmqClusterPerPodServiceSpec{Type: corev1.ServiceTypeNodePort},
+				},
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+
+			serviceExists := func(name string) func() bool {
+				return func() bool {
+					_, err := clientSet.CoreV1().Services(rabbitmqCluster.Namespace).Get(ctx, name, metav1.GetOptions{})
+					return err == nil
+				}
+			}
+			for _, name := range []string{"rabbitmq-per-pod-rabbitmq-server-0", "rabbitmq-per-pod-rabbitmq-server-1", "rabbitmq-per-pod-rabbitmq-server-2"} {
+				Eventually(serviceExists(name), 5).Should(BeTrue())
+			}
+
+			Expect(updateWithRetry(rabbitmqCluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Spec.Replicas = &one
+			})).To(Succeed())
+
+			Eventually(serviceExists("rabbitmq-per-pod-rabbitmq-server-2"), 5).Should(BeFalse())
+			Eventually(serviceExists("rabbitmq-per-pod-rabbitmq-server-1"), 5).Should(BeFalse())
+			Consistently(serviceExists("rabbitmq-per-pod-rabbitmq-server-0"), 2).Should(BeTrue())
+		})
+	})
+
+	Context("Additional Services", func() {
+		AfterEach(func() {
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+		})
+
+		It("creates the additional Services and deletes the ones removed from the spec", func() {
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-additional-services",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: &one,
+					AdditionalServices: []rabbitmqv1beta1.RabbitmqClusterAdditionalServiceSpec{
+						{Name: "amqp-internal", Ports: []string{"amqp"}},
+						{Name: "management-external", Type: corev1.ServiceTypeNodePort, Ports: []string{"management"}},
+					},
+				},
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+
+			getService := func(name string) func() (*corev1.Service, error) {
+				return func() (*corev1.Service, error) {
+					return clientSet.CoreV1().Services(rabbitmqCluster.Namespace).Get(ctx, name, metav1.GetOptions{})
+				}
+			}
+			Eventually(getService("rabbitmq-additional-services-rabbitmq-amqp-internal"), 5).Should(
+				WithTransform(func(s *corev1.Service) corev1.ServiceType { return s.Spec.Type }, Equal(corev1.ServiceTypeClusterIP)))
+			Eventually(getService("rabbitmq-additional-services-rabbitmq-management-external"), 5).Should(
+				WithTransform(func(s *corev1.Service) corev1.ServiceType { return s.Spec.Type }, Equal(corev1.ServiceTypeNodePort)))
+
+			Expect(updateWithRetry(rabbitmqCluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Spec.AdditionalServices = r.Spec.AdditionalServices[:1]
+			})).To(Succeed())
+
+			Eventually(func() error {
+				_, err := getService("rabbitmq-additional-services-rabbitmq-management-external")()
+				return err
+			}, 5).Should(HaveOccurred())
+			Consistently(func() error {
+				_, err := getService("rabbitmq-additional-services-rabbitmq-amqp-internal")()
+				return err
+			}, 2).Should(Succeed())
+		})
+	})
+
+	Context("Stale child resources", func() {
+		var three int32 = 3
+
+		resourceExists := func(gvk schema.GroupVersionKind, name string) func() bool {
+			return func() bool {
+				obj := &unstructured.Unstructured{}
+				obj.SetGroupVersionKind(gvk)
+				return client.Get(ctx, types.NamespacedName{Namespace: defaultNamespace, Name: name}, obj) == nil
+			}
+		}
+
+		// enableThenDisable creates a RabbitmqCluster with the given spec, waits for the child resource to be created,
+		// then applies disable and waits for the reconciler to delete the child resource again
+		enableThenDisable := func(name string, spec rabbitmqv1beta1.RabbitmqClusterSpec, disable func(r *rabbitmqv1beta1.RabbitmqCluster), gvk schema.GroupVersionKind, childName string) {
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: defaultNamespace,
+				},
+				Spec: spec,
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+			Eventually(resourceExists(gvk, rabbitmqCluster.ChildResourceName(childName)), 5).Should(BeTrue())
+
+			Expect(updateWithRetry(rabbitmqCluster, disable)).To(Succeed())
+			Eventually(resourceExists(gvk, rabbitmqCluster.ChildResourceName(childName)), 5).Should(BeFalse())
+		}
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+		})
+
+		It("deletes the NetworkPolicy when spec.networkPolicy is removed", func() {
+			enableThenDisable("rabbitmq-stale-netpol",
+				rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas:      &one,
+					NetworkPolicy: &rabbitmqv1beta1.RabbitmqClusterNetworkPolicySpec{},
+				},
+				func(r *rabbitmqv1beta1.RabbitmqCluster) { r.Spec.NetworkPolicy = nil },
+				networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"), "server")
+		})
+
+		It("deletes the PodDisruptionBudget when the cluster is scaled down to a single node", func() {
+			enableThenDisable("rabbitmq-stale-pdb",
+				rabbitmqv1beta1.RabbitmqClusterSpec{Replicas: &three},
+				func(r *rabbitmqv1beta1.RabbitmqCluster) { r.Spec.Replicas = &one },
+				policyv1beta1.SchemeGroupVersion.WithKind("PodDisruptionBudget"), "server")
+		})
+
+		It("deletes the Ingress when spec.management.ingress is removed", func() {
+			enableThenDisable("rabbitmq-stale-ingress",
+				rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: &one,
+					Management: &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+						Ingress: &rabbitmqv1beta1.RabbitmqClusterManagementIngressSpec{Host: "rabbitmq.example.com"},
+					},
+				},
+				func(r *rabbitmqv1beta1.RabbitmqCluster) { r.Spec.Management = nil },
+				networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"), "management")
+		})
+
+		It("deletes the HTTPRoute when spec.management.ingress is removed", func() {
+			enableThenDisable("rabbitmq-stale-httproute",
+				rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: &one,
+					Management: &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+						Ingress: &rabbitmqv1beta1.RabbitmqClusterManagementIngressSpec{Kind: "HTTPRoute", Host: "rabbitmq.example.com"},
+					},
+				},
+				func(r *rabbitmqv1beta1.RabbitmqCluster) { r.Spec.Management = nil },
+				resource.GatewayGroupVersion.WithKind(resource.HTTPRouteKind), "management")
+		})
+
+		It("deletes the ServiceMonitor when spec.monitoring is removed", func() {
+			enableThenDisable("rabbitmq-stale-servicemonitor",
+				rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas:   &one,
+					Monitoring: &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{MonitorKind: "ServiceMonitor"},
+				},
+				func(r *rabbitmqv1beta1.RabbitmqCluster) { r.Spec.Monitoring = nil },
+				resource.MonitoringGroupVersion.WithKind(resource.ServiceMonitorKind), "monitor")
+		})
+
+		It("deletes the PodMonitor when spec.monitoring.monitorKind is switched to ServiceMonitor", func() {
+			enableThenDisable("rabbitmq-stale-podmonitor",
+				rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas:   &one,
+					Monitoring: &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{MonitorKind: "PodMonitor"},
+				},
+				func(r *rabbitmqv1beta1.RabbitmqCluster) { r.Spec.Monitoring.MonitorKind = "ServiceMonitor" },
+				resource.MonitoringGroupVersion.WithKind(resource.PodMonitorKind), "monitor")
+			Eventually(resourceExists(resource.MonitoringGroupVersion.WithKind(resource.ServiceMonitorKind), rabbitmqCluster.ChildResourceName("monitor")), 5).Should(BeTrue())
+		})
+
+		It("deletes the PrometheusRule when spec.monitoring.disableAlerts is set", func() {
+			enableThenDisable("rabbitmq-stale-prometheusrule",
+				rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas:   &one,
+					Monitoring: &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{},
+				},
+				func(r *rabbitmqv1beta1.RabbitmqCluster) { r.Spec.Monitoring.DisableAlerts = true },
+				resource.MonitoringGroupVersion.WithKind(resource.PrometheusRuleKind), "prometheus-rule")
+		})
+	})
+
+	Context("PersistentVolumeClaim retention", func() {
+		createClusterWithPVC := func(name string, policy rabbitmqv1beta1.PersistentVolumeClaimRetentionPolicyType) *corev1.PersistentVolumeClaim {
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: &one,
+					Persistence: rabbitmqv1beta1.RabbitmqClusterPersistenceSpec{
+						RetentionPolicy: &rabbitmqv1beta1.PersistentVolumeClaimRetentionPolicy{WhenDeleted: policy},
+					},
+				},
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterCreation(ctx, rabbitmqCluster, client)
+
+			// envtest runs no StatefulSet controller, so the claim of the first node is created by the test
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "persistence-" + rabbitmqCluster.ChildResourceName("server") + "-0",
+					Namespace: defaultNamespace,
+					Labels:    map[string]string{"app.kubernetes.io/name": name},
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: k8sresource.MustParse("1Gi")},
+					},
+				},
+			}
+			Expect(controllerutil.SetControllerReference(rabbitmqCluster, pvc, scheme)).To(Succeed())
+			Expect(client.Create(ctx, pvc)).To(Succeed())
+			return pvc
+		}
+
+		It("releases the PersistentVolumeClaims when they are retained", func() {
+			pvc := createClusterWithPVC("rabbitmq-retain-pvc", rabbitmqv1beta1.RetainPersistentVolumeClaimRetentionPolicyType)
+
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterDeletion(ctx, rabbitmqCluster, client)
+
+			retained := &corev1.PersistentVolumeClaim{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, retained)).To(Succeed())
+			Expect(retained.OwnerReferences).To(BeEmpty())
+			Eventually(func() string {
+				return aggregateEventMsgs(ctx, rabbitmqCluster, "RetainedPersistentVolumeClaims")
+			}, 5).Should(ContainSubstring(pvc.Name))
+			Expect(client.Delete(ctx, retained)).To(Succeed())
+		})
+
+		It("reports the PersistentVolumeClaims retained on scale down", func() {
+			createClusterWithPVC("rabbitmq-retain-scaled-pvc", "")
+			Expect(updateWithRetry(rabbitmqCluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				three := int32(3)
+				r.Spec.Replicas = &three
+			})).To(Succeed())
+			Eventually(func() int32 {
+				return *statefulSet(ctx, rabbitmqCluster).Spec.Replicas
+			}, 5).Should(Equal(int32(3)))
+
+			var pvcNames []string
+			for _, ordinal := range []int{1, 2} {
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("persistence-%s-%d", rabbitmqCluster.ChildResourceName("server"), ordinal),
+						Namespace: defaultNamespace,
+						Labels:    map[string]string{"app.kubernetes.io/name": rabbitmqCluster.Name},
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: k8sresource.MustParse("1Gi")},
+						},
+					},
+				}
+				Expect(controllerutil.SetControllerReference(rabbitmqCluster, pvc, scheme)).To(Succeed())
+				Expect(client.Create(ctx, pvc)).To(Succeed())
+				pvcNames = append(pvcNames, pvc.Name)
+			}
+
+			Expect(updateWithRetry(rabbitmqCluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Spec.Replicas = &one
+			})).To(Succeed())
+			Eventually(func() string {
+				return aggregateEventMsgs(ctx, rabbitmqCluster, "RetainedPersistentVolumeClaims")
+			}, 5).Should(ContainSubstring("Kept PersistentVolumeClaims of scaled down nodes " + strings.Join(pvcNames, ", ")))
+
+			for _, name := range pvcNames {
+				retained := &corev1.PersistentVolumeClaim{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: name, Namespace: defaultNamespace}, retained)).To(Succeed())
+				Expect(retained.DeletionTimestamp).To(BeNil())
+			}
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterDeletion(ctx, rabbitmqCluster, client)
+		})
+
+		It("deletes the PersistentVolumeClaims by default", func() {
+			pvc := createClusterWithPVC("rabbitmq-delete-pvc", "")
+
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterDeletion(ctx, rabbitmqCluster, client)
+
+			// the claim may be held by the pvc-protection finalizer, which no controller removes in envtest
+			Eventually(func() bool {
+				deleted := &corev1.PersistentVolumeClaim{}
+				err := client.Get(ctx, types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, deleted)
+				return apierrors.IsNotFound(err) || !deleted.DeletionTimestamp.IsZero()
+			}, 5).Should(BeTrue())
+		})
+	})
+
 	Context("Client service configurations", func() {
 		AfterEach(func() {
 			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
@@ -623,6 +1003,132 @@ var _ = Describe("RabbitmqclusterController", func() {
 		})
 	})
 
+	Context("StorageClass migration", func() {
+		var three int32 = 3
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+		})
+
+		It("recreates the StatefulSet with the new StorageClass and reports the migration", func() {
+			oldStorageClass := "old-storage-class"
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-storage-migration",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: &three,
+					Persistence: rabbitmqv1beta1.RabbitmqClusterPersistenceSpec{
+						StorageClassName: &oldStorageClass,
+					},
+				},
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterCreation(ctx, rabbitmqCluster, client)
+			oldSts := statefulSet(ctx, rabbitmqCluster)
+
+			newStorageClass := "new-storage-class"
+			Expect(updateWithRetry(rabbitmqCluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Spec.Persistence.StorageClassName = &newStorageClass
+			})).To(Succeed())
+
+			// envtest runs no garbage collector to orphan the Pods and remove the orphan finalizer
+			Eventually(func() bool {
+				sts := &appsv1.StatefulSet{}
+				if err := client.Get(ctx, types.NamespacedName{Name: oldSts.Name, Namespace: oldSts.Namespace}, sts); err != nil {
+					return false
+				}
+				if sts.UID != oldSts.UID || sts.DeletionTimestamp.IsZero() {
+					return false
+				}
+				sts.Finalizers = nil
+				return client.Update(ctx, sts) == nil
+			}, 5).Should(BeTrue())
+
+			Eventually(func() string {
+				sts := &appsv1.StatefulSet{}
+				if err := client.Get(ctx, types.NamespacedName{Name: oldSts.Name, Namespace: oldSts.Namespace}, sts); err != nil || sts.UID == oldSts.UID {
+					return ""
+				}
+				return *sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName
+			}, 5).Should(Equal(newStorageClass))
+
+			Eventually(func() string {
+				rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: rabbitmqCluster.Name, Namespace: rabbitmqCluster.Namespace}, rmq)).To(Succeed())
+				if rmq.Status.StorageMigration == nil {
+					return ""
+				}
+				return rmq.Status.StorageMigration.StorageClassName
+			}, 5).Should(Equal(newStorageClass))
+			Eventually(func() string {
+				return aggregateEventMsgs(ctx, rabbitmqCluster, "StorageMigrationStarted")
+			}, 5).Should(ContainSubstring(newStorageClass))
+		})
+
+		It("warns that a RabbitmqCluster of fewer than 3 nodes cannot be moved", func() {
+			var two int32 = 2
+			oldStorageClass := "old-storage-class"
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-storage-migration-unsupported",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: &two,
+					Persistence: rabbitmqv1beta1.RabbitmqClusterPersistenceSpec{
+						StorageClassName: &oldStorageClass,
+					},
+				},
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterCreation(ctx, rabbitmqCluster, client)
+			oldSts := statefulSet(ctx, rabbitmqCluster)
+
+			newStorageClass := "new-storage-class"
+			Expect(updateWithRetry(rabbitmqCluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Spec.Persistence.StorageClassName = &newStorageClass
+			})).To(Succeed())
+
+			Eventually(func() string {
+				return aggregateEventMsgs(ctx, rabbitmqCluster, "StorageMigrationUnsupported")
+			}, 5).Should(ContainSubstring("fewer than 3 nodes"))
+			// trigger more reconciliations of the same generation
+			Expect(updateWithRetry(rabbitmqCluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Labels = map[string]string{"trigger": "reconcile"}
+			})).To(Succeed())
+			Consistently(func() int32 {
+				events, err := clientSet.CoreV1().Events(rabbitmqCluster.Namespace).List(ctx, metav1.ListOptions{
+					FieldSelector: fmt.Sprintf("involvedObject.name=%s,reason=StorageMigrationUnsupported", rabbitmqCluster.Name),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				var count int32
+				for _, event := range events.Items {
+					count += event.Count
+				}
+				return count
+			}, 3).Should(Equal(int32(1)))
+
+			sts := statefulSet(ctx, rabbitmqCluster)
+			Expect(sts.UID).To(Equal(oldSts.UID))
+			Expect(sts.DeletionTimestamp.IsZero()).To(BeTrue())
+
+			By("reporting it in the NoWarnings condition")
+			rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: rabbitmqCluster.Name, Namespace: rabbitmqCluster.Namespace}, rmq)).To(Succeed())
+			var noWarnings *status.RabbitmqClusterCondition
+			for i := range rmq.Status.Conditions {
+				if rmq.Status.Conditions[i].Type == status.NoWarnings {
+					noWarnings = &rmq.Status.Conditions[i]
+				}
+			}
+			Expect(noWarnings).NotTo(BeNil())
+			Expect(noWarnings.Status).To(Equal(corev1.ConditionFalse))
+			Expect(noWarnings.Reason).To(Equal("StorageMigrationUnsupported"))
+		})
+	})
+
 	Context("Custom Resource updates", func() {
 		var (
 			rabbitmqCluster   *rabbitmqv1beta1.RabbitmqCluster
@@ -903,6 +1409,47 @@ var _ = Describe("RabbitmqclusterController", func() {
 		})
 	})
 
+	Context("Reconcile metrics", func() {
+		// reconcileObserved reports whether the reconcile duration histogram has a series for the given RabbitmqCluster
+		reconcileObserved := func(name string) func() bool {
+			return func() bool {
+				families, err := ctrlmetrics.Registry.Gather()
+				Expect(err).NotTo(HaveOccurred())
+				for _, family := range families {
+					if family.GetName() != "rabbitmq_cluster_operator_reconcile_duration_seconds" {
+						continue
+					}
+					for _, metric := range family.GetMetric() {
+						for _, label := range metric.GetLabel() {
+							if label.GetName() == "name" && label.GetValue() == name {
+								return true
+							}
+						}
+					}
+				}
+				return false
+			}
+		}
+
+		It("does not recreate the series of a deleted cluster", func() {
+			rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-metrics",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{Replicas: &one},
+			}
+			Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterCreation(ctx, rabbitmqCluster, client)
+			Eventually(reconcileObserved(rabbitmqCluster.Name), 5).Should(BeTrue())
+
+			Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+			waitForClusterDeletion(ctx, rabbitmqCluster, client)
+			Eventually(reconcileObserved(rabbitmqCluster.Name), 5).Should(BeFalse())
+			Consistently(reconcileObserved(rabbitmqCluster.Name), 2).Should(BeFalse())
+		})
+	})
+
 	Context("Recreate child resources after deletion", func() {
 		var (
 			clientServiceName   string
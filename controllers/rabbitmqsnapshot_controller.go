@@ -0,0 +1,330 @@
+/*
+RabbitMQ Cluster Operator
+
+Copyright 2020 VMware, Inc. All Rights Reserved.
+
+This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+
+This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// how often the VolumeSnapshots are checked while they are being taken
+	snapshotRequeueInterval = 10 * time.Second
+	// keeps a RabbitmqSnapshot stopping nodes until they are started again
+	snapshotDeletionFinalizer = "deletion.finalizers.rabbitmqsnapshots.rabbitmq.com"
+)
+
+// RabbitmqSnapshotReconciler reconciles a RabbitmqSnapshot object
+type RabbitmqSnapshotReconciler struct {
+	client.Client
+	Log           logr.Logger
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	ClusterConfig *rest.Config
+	Clientset     *kubernetes.Clientset
+}
+
+// +kubebuilder:rbac:groups=rabbitmq.com,resources=rabbitmqsnapshots,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=rabbitmq.com,resources=rabbitmqsnapshots/status,verbs=get;update
+// +kubebuilder:rbac:groups=rabbitmq.com,resources=rabbitmqsnapshots/finalizers,verbs=update
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
+
+func (r *RabbitmqSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	snapshot := &rabbitmqv1beta1.RabbitmqSnapshot{}
+	if err := r.Get(ctx, req.NamespacedName, snapshot); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !snapshot.DeletionTimestamp.IsZero() {
+		// the VolumeSnapshots are deleted with the RabbitmqSnapshot by the garbage collector
+		return ctrl.Result{}, r.prepareForDeletion(ctx, snapshot)
+	}
+
+	if snapshot.Spec.StopNodes && !controllerutil.ContainsFinalizer(snapshot, snapshotDeletionFinalizer) {
+		controllerutil.AddFinalizer(snapshot, snapshotDeletionFinalizer)
+		if err := r.Update(ctx, snapshot); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch snapshot.Status.Phase {
+	case "", rabbitmqv1beta1.PendingRabbitmqSnapshotPhase:
+		return r.startSnapshot(ctx, snapshot)
+	case rabbitmqv1beta1.StoppingNodesRabbitmqSnapshotPhase:
+		return r.stopNodes(snapshot)
+	case rabbitmqv1beta1.SnapshottingRabbitmqSnapshotPhase:
+		return r.takeVolumeSnapshots(ctx, snapshot)
+	case rabbitmqv1beta1.StartingNodesRabbitmqSnapshotPhase:
+		return r.startNodes(snapshot)
+	case rabbitmqv1beta1.CompletedRabbitmqSnapshotPhase:
+		if snapshot.Status.ReadyToUse {
+			return ctrl.Result{}, nil
+		}
+		if _, _, err := r.refreshVolumeSnapshots(ctx, snapshot); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Status().Update(ctx, snapshot); err != nil {
+			return ctrl.Result{}, err
+		}
+		if !snapshot.Status.ReadyToUse {
+			return ctrl.Result{RequeueAfter: snapshotRequeueInterval}, nil
+		}
+		r.Recorder.Event(snapshot, corev1.EventTypeNormal, "ReadyToUse", "All VolumeSnapshots are ready to use")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// startSnapshot - helper function that waits for the RabbitmqCluster to be ready, and records the VolumeSnapshot to take of each node
+func (r *RabbitmqSnapshotReconciler) startSnapshot(ctx context.Context, snapshot *rabbitmqv1beta1.RabbitmqSnapshot) (ctrl.Result, error) {
+	if !kindInstalled(ctx, r.Client, snapshot.Namespace, resource.VolumeSnapshotGroupVersion.WithKind(resource.VolumeSnapshotKind)) {
+		return r.setPending(ctx, snapshot, fmt.Sprintf("the %s CRD of the CSI external snapshotter is not installed", resource.VolumeSnapshotKind))
+	}
+
+	rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+	clusterName := snapshot.Spec.RabbitmqClusterReference.Name
+	if err := r.Get(ctx, types.NamespacedName{Name: clusterName, Namespace: snapshot.Namespace}, rmq); err != nil {
+		if errors.IsNotFound(err) {
+			return r.setPending(ctx, snapshot, fmt.Sprintf("RabbitmqCluster %s does not exist", clusterName))
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !rmq.PersistenceEnabled() {
+		snapshot.Status.Phase = rabbitmqv1beta1.FailedRabbitmqSnapshotPhase
+		snapshot.Status.Message = fmt.Sprintf("RabbitmqCluster %s has no persistent storage", clusterName)
+		r.Recorder.Event(snapshot, corev1.EventTypeWarning, "SnapshotFailed", snapshot.Status.Message)
+		return ctrl.Result{}, r.Status().Update(ctx, snapshot)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rmq.ChildResourceName("server"), Namespace: rmq.Namespace}, sts); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	if sts.Spec.Replicas == nil || *sts.Spec.Replicas == 0 || sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+		return r.setPending(ctx, snapshot, fmt.Sprintf("not all nodes of RabbitmqCluster %s are ready", clusterName))
+	}
+
+	// the users are restored with the data, and the default user Secret is deleted with the RabbitmqCluster
+	adminSecret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rmq.ChildResourceName(resource.AdminSecretName), Namespace: rmq.Namespace}, adminSecret); err != nil {
+		return ctrl.Result{}, err
+	}
+	defaultUser, err := resource.DefaultUserSecret(snapshot, adminSecret, r.Scheme)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, defaultUser); err != nil && !errors.IsAlreadyExists(err) {
+		return ctrl.Result{}, fmt.Errorf("cannot create Secret %s: %w", defaultUser.Name, err)
+	}
+	snapshot.Status.DefaultUserSecretName = defaultUser.Name
+
+	snapshot.Status.Replicas = *sts.Spec.Replicas
+	snapshot.Status.VolumeSnapshots = nil
+	for i := int32(0); i < snapshot.Status.Replicas; i++ {
+		podName := fmt.Sprintf("%s-%d", sts.Name, i)
+		snapshot.Status.VolumeSnapshots = append(snapshot.Status.VolumeSnapshots, rabbitmqv1beta1.RabbitmqSnapshotVolume{
+			Node:                      podName,
+			PersistentVolumeClaimName: resource.PersistenceClaimName(podName),
+			VolumeSnapshotName:        fmt.Sprintf("%s-%d", snapshot.Name, i),
+		})
+	}
+	snapshot.Status.Message = ""
+	snapshot.Status.Phase = rabbitmqv1beta1.SnapshottingRabbitmqSnapshotPhase
+	if snapshot.Spec.StopNodes {
+		snapshot.Status.Phase = rabbitmqv1beta1.StoppingNodesRabbitmqSnapshotPhase
+	}
+	if err := r.Status().Update(ctx, snapshot); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// stopNodes - helper function that stops the RabbitMQ application from the highest ordinal to the lowest
+// the node stopped last has the most recent data, and must be started first
+func (r *RabbitmqSnapshotReconciler) stopNodes(snapshot *rabbitmqv1beta1.RabbitmqSnapshot) (ctrl.Result, error) {
+	volumes := snapshot.Status.VolumeSnapshots
+	for i := len(volumes) - 1; i >= 0; i-- {
+		if err := r.execRabbitmqCommand(snapshot, volumes[i].Node, "rabbitmqctl stop_app"); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	r.Recorder.Event(snapshot, corev1.EventTypeNormal, "StoppedNodes",
+		fmt.Sprintf("Stopped RabbitMQ on all nodes of RabbitmqCluster %s", snapshot.Spec.RabbitmqClusterReference.Name))
+
+	snapshot.Status.Phase = rabbitmqv1beta1.SnapshottingRabbitmqSnapshotPhase
+	return ctrl.Result{Requeue: true}, r.Status().Update(context.Background(), snapshot)
+}
+
+// takeVolumeSnapshots - helper function that creates the VolumeSnapshots, and waits until the CSI driver has taken all of them
+func (r *RabbitmqSnapshotReconciler) takeVolumeSnapshots(ctx context.Context, snapshot *rabbitmqv1beta1.RabbitmqSnapshot) (ctrl.Result, error) {
+	for _, volume := range snapshot.Status.VolumeSnapshots {
+		volumeSnapshot, err := resource.VolumeSnapshot(snapshot, volume, r.Scheme)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, volumeSnapshot); err != nil && !errors.IsAlreadyExists(err) {
+			return ctrl.Result{}, fmt.Errorf("cannot create VolumeSnapshot %s: %w", volume.VolumeSnapshotName, err)
+		}
+	}
+
+	taken, failure, err := r.refreshVolumeSnapshots(ctx, snapshot)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if failure == "" && !taken {
+		return ctrl.Result{RequeueAfter: snapshotRequeueInterval}, r.Status().Update(ctx, snapshot)
+	}
+
+	snapshot.Status.Message = failure
+	switch {
+	case snapshot.Spec.StopNodes:
+		snapshot.Status.Phase = rabbitmqv1beta1.StartingNodesRabbitmqSnapshotPhase
+	case failure != "":
+		snapshot.Status.Phase = rabbitmqv1beta1.FailedRabbitmqSnapshotPhase
+		r.Recorder.Event(snapshot, corev1.EventTypeWarning, "SnapshotFailed", failure)
+	default:
+		snapshot.Status.Phase = rabbitmqv1beta1.CompletedRabbitmqSnapshotPhase
+		r.Recorder.Event(snapshot, corev1.EventTypeNormal, "SnapshotCompleted", "Took a VolumeSnapshot of every node")
+	}
+	return ctrl.Result{Requeue: true}, r.Status().Update(ctx, snapshot)
+}
+
+// startNodes - helper function that starts the RabbitMQ application again, from the lowest ordinal to the highest
+func (r *RabbitmqSnapshotReconciler) startNodes(snapshot *rabbitmqv1beta1.RabbitmqSnapshot) (ctrl.Result, error) {
+	if err := r.startApps(context.Background(), snapshot); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if snapshot.Status.Message != "" {
+		snapshot.Status.Phase = rabbitmqv1beta1.FailedRabbitmqSnapshotPhase
+		r.Recorder.Event(snapshot, corev1.EventTypeWarning, "SnapshotFailed", snapshot.Status.Message)
+	} else {
+		snapshot.Status.Phase = rabbitmqv1beta1.CompletedRabbitmqSnapshotPhase
+		r.Recorder.Event(snapshot, corev1.EventTypeNormal, "SnapshotCompleted", "Took a VolumeSnapshot of every node")
+	}
+	return ctrl.Result{Requeue: true}, r.Status().Update(context.Background(), snapshot)
+}
+
+// refreshVolumeSnapshots - helper function that copies the readiness of the VolumeSnapshots into the status of the RabbitmqSnapshot
+// it returns whether all VolumeSnapshots have been taken, i.e. the volumes may be written to again, and the error of a failed VolumeSnapshot
+func (r *RabbitmqSnapshotReconciler) refreshVolumeSnapshots(ctx context.Context, snapshot *rabbitmqv1beta1.RabbitmqSnapshot) (bool, string, error) {
+	taken := true
+	snapshot.Status.ReadyToUse = true
+	for i, volume := range snapshot.Status.VolumeSnapshots {
+		volumeSnapshot := &unstructured.Unstructured{}
+		volumeSnapshot.SetGroupVersionKind(resource.VolumeSnapshotGroupVersion.WithKind(resource.VolumeSnapshotKind))
+		if err := r.Get(ctx, types.NamespacedName{Name: volume.VolumeSnapshotName, Namespace: snapshot.Namespace}, volumeSnapshot); err != nil {
+			return false, "", err
+		}
+
+		if message, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "error", "message"); found {
+			return false, fmt.Sprintf("VolumeSnapshot %s failed: %s", volume.VolumeSnapshotName, message), nil
+		}
+		readyToUse, _, _ := unstructured.NestedBool(volumeSnapshot.Object, "status", "readyToUse")
+		_, created, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "creationTime")
+
+		snapshot.Status.VolumeSnapshots[i].ReadyToUse = readyToUse
+		snapshot.Status.ReadyToUse = snapshot.Status.ReadyToUse && readyToUse
+		taken = taken && (created || readyToUse)
+	}
+	return taken, "", nil
+}
+
+// startApps - helper function that runs start_app on every node of the snapshotted RabbitmqCluster, from the lowest ordinal to the highest
+// start_app does nothing on a running node; nodes whose Pod is gone are skipped, as RabbitMQ starts with their new Pod
+func (r *RabbitmqSnapshotReconciler) startApps(ctx context.Context, snapshot *rabbitmqv1beta1.RabbitmqSnapshot) error {
+	for _, volume := range snapshot.Status.VolumeSnapshots {
+		err := r.Get(ctx, types.NamespacedName{Name: volume.Node, Namespace: snapshot.Namespace}, &corev1.Pod{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.execRabbitmqCommand(snapshot, volume.Node, "rabbitmqctl start_app"); err != nil {
+			return err
+		}
+	}
+	r.Recorder.Event(snapshot, corev1.EventTypeNormal, "StartedNodes",
+		fmt.Sprintf("Started RabbitMQ on all nodes of RabbitmqCluster %s", snapshot.Spec.RabbitmqClusterReference.Name))
+	return nil
+}
+
+// prepareForDeletion - helper function that starts the nodes again when the RabbitmqSnapshot is deleted while they may be stopped,
+// before releasing the finalizer
+func (r *RabbitmqSnapshotReconciler) prepareForDeletion(ctx context.Context, snapshot *rabbitmqv1beta1.RabbitmqSnapshot) error {
+	if !controllerutil.ContainsFinalizer(snapshot, snapshotDeletionFinalizer) {
+		return nil
+	}
+
+	switch snapshot.Status.Phase {
+	case rabbitmqv1beta1.StoppingNodesRabbitmqSnapshotPhase,
+		rabbitmqv1beta1.SnapshottingRabbitmqSnapshotPhase,
+		rabbitmqv1beta1.StartingNodesRabbitmqSnapshotPhase:
+		if snapshot.Spec.StopNodes {
+			if err := r.startApps(ctx, snapshot); err != nil {
+				r.Log.Error(err, "Failed to start RabbitMQ nodes of a deleted RabbitmqSnapshot",
+					"namespace", snapshot.Namespace,
+					"name", snapshot.Name)
+				return err
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(snapshot, snapshotDeletionFinalizer)
+	return r.Update(ctx, snapshot)
+}
+
+func (r *RabbitmqSnapshotReconciler) setPending(ctx context.Context, snapshot *rabbitmqv1beta1.RabbitmqSnapshot, message string) (ctrl.Result, error) {
+	if snapshot.Status.Phase != rabbitmqv1beta1.PendingRabbitmqSnapshotPhase || snapshot.Status.Message != message {
+		snapshot.Status.Phase = rabbitmqv1beta1.PendingRabbitmqSnapshotPhase
+		snapshot.Status.Message = message
+		r.Log.Info("RabbitmqSnapshot is pending: "+message, "namespace", snapshot.Namespace, "name", snapshot.Name)
+		if err := r.Status().Update(ctx, snapshot); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: snapshotRequeueInterval}, nil
+}
+
+func (r *RabbitmqSnapshotReconciler) execRabbitmqCommand(snapshot *rabbitmqv1beta1.RabbitmqSnapshot, podName, command string) error {
+	stdout, stderr, err := execInPod(r.ClusterConfig, r.Clientset, snapshot.Namespace, podName, "rabbitmq", "sh", "-c", command)
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf(
+			"Failed to run command %s on pod %s in namespace %s with output: %s %s",
+			command, podName, snapshot.Namespace, stdout, stderr))
+	}
+	return err
+}
+
+func (r *RabbitmqSnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rabbitmqv1beta1.RabbitmqSnapshot{}).
+		Complete(r)
+}
@@ -0,0 +1,228 @@
+/*
+RabbitMQ Cluster Operator
+
+Copyright 2020 VMware, Inc. All Rights Reserved.
+
+This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+
+This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+var _ = Describe("RabbitmqSnapshotController", func() {
+	var (
+		rabbitmqCluster  *rabbitmqv1beta1.RabbitmqCluster
+		one              int32 = 1
+		defaultNamespace       = "default"
+		ctx                    = context.Background()
+		getSnapshot            = func(name string) *rabbitmqv1beta1.RabbitmqSnapshot {
+			snapshot := &rabbitmqv1beta1.RabbitmqSnapshot{}
+			ExpectWithOffset(1, client.Get(ctx, types.NamespacedName{Name: name, Namespace: defaultNamespace}, snapshot)).To(Succeed())
+			return snapshot
+		}
+	)
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, rabbitmqCluster)).To(Succeed())
+		waitForClusterDeletion(ctx, rabbitmqCluster, client)
+	})
+
+	It("takes a VolumeSnapshot of each node and reports when they are ready to use", func() {
+		rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-snapshot",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: &one,
+			},
+		}
+		Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+		waitForClusterCreation(ctx, rabbitmqCluster, client)
+
+		// envtest runs no StatefulSet controller, so the test reports the node as ready
+		sts := statefulSet(ctx, rabbitmqCluster)
+		sts.Status.Replicas = 1
+		sts.Status.ReadyReplicas = 1
+		Expect(client.Status().Update(ctx, sts)).To(Succeed())
+
+		snapshot := &rabbitmqv1beta1.RabbitmqSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nightly",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqSnapshotSpec{
+				RabbitmqClusterReference: rabbitmqv1beta1.RabbitmqClusterReference{Name: rabbitmqCluster.Name},
+			},
+		}
+		Expect(client.Create(ctx, snapshot)).To(Succeed())
+
+		volumeSnapshot := &unstructured.Unstructured{}
+		volumeSnapshot.SetGroupVersionKind(resource.VolumeSnapshotGroupVersion.WithKind(resource.VolumeSnapshotKind))
+		Eventually(func() error {
+			return client.Get(ctx, types.NamespacedName{Name: "nightly-0", Namespace: defaultNamespace}, volumeSnapshot)
+		}, 5).Should(Succeed())
+		source, _, _ := unstructured.NestedString(volumeSnapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		Expect(source).To(Equal("persistence-rabbitmq-snapshot-rabbitmq-server-0"))
+		Expect(getSnapshot("nightly").Status.Phase).To(Equal(rabbitmqv1beta1.SnapshottingRabbitmqSnapshotPhase))
+
+		// the test plays the part of the CSI snapshot controller
+		Expect(unstructured.SetNestedField(volumeSnapshot.Object, map[string]interface{}{
+			"creationTime": time.Now().Format(time.RFC3339),
+			"readyToUse":   true,
+		}, "status")).To(Succeed())
+		Expect(client.Status().Update(ctx, volumeSnapshot)).To(Succeed())
+
+		Eventually(func() bool {
+			return getSnapshot("nightly").Status.ReadyToUse
+		}, 15).Should(BeTrue())
+		snapshot = getSnapshot("nightly")
+		Expect(snapshot.Status.Phase).To(Equal(rabbitmqv1beta1.CompletedRabbitmqSnapshotPhase))
+		Expect(snapshot.Status.Replicas).To(Equal(int32(1)))
+		Expect(snapshot.Status.VolumeSnapshots).To(ConsistOf(rabbitmqv1beta1.RabbitmqSnapshotVolume{
+			Node:                      "rabbitmq-snapshot-rabbitmq-server-0",
+			PersistentVolumeClaimName: "persistence-rabbitmq-snapshot-rabbitmq-server-0",
+			VolumeSnapshotName:        "nightly-0",
+			ReadyToUse:                true,
+		}))
+
+		By("keeping a copy of the default user Secret")
+		Expect(snapshot.Status.DefaultUserSecretName).To(Equal("nightly-default-user"))
+		adminSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: rabbitmqCluster.ChildResourceName(resource.AdminSecretName), Namespace: defaultNamespace}, adminSecret)).To(Succeed())
+		defaultUser := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: "nightly-default-user", Namespace: defaultNamespace}, defaultUser)).To(Succeed())
+		Expect(defaultUser.Data).To(Equal(adminSecret.Data))
+
+		Expect(client.Delete(ctx, snapshot)).To(Succeed())
+	})
+
+	It("starts the nodes again before releasing the finalizer of a RabbitmqSnapshot stopping them", func() {
+		rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-snapshot-finalizer",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: &one,
+			},
+		}
+		Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+		waitForClusterCreation(ctx, rabbitmqCluster, client)
+
+		snapshot := &rabbitmqv1beta1.RabbitmqSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "stopped",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqSnapshotSpec{
+				RabbitmqClusterReference: rabbitmqv1beta1.RabbitmqClusterReference{Name: rabbitmqCluster.Name},
+				StopNodes:                true,
+			},
+		}
+		Expect(client.Create(ctx, snapshot)).To(Succeed())
+		Eventually(func() []string {
+			return getSnapshot("stopped").Finalizers
+		}, 5).Should(ConsistOf("deletion.finalizers.rabbitmqsnapshots.rabbitmq.com"))
+
+		// envtest runs no Pods, so there is no node to start again
+		Expect(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			snapshot = getSnapshot("stopped")
+			snapshot.Status.Phase = rabbitmqv1beta1.SnapshottingRabbitmqSnapshotPhase
+			snapshot.Status.VolumeSnapshots = []rabbitmqv1beta1.RabbitmqSnapshotVolume{{
+				Node:                      "rabbitmq-snapshot-finalizer-rabbitmq-server-0",
+				PersistentVolumeClaimName: "persistence-rabbitmq-snapshot-finalizer-rabbitmq-server-0",
+				VolumeSnapshotName:        "stopped-0",
+			}}
+			return client.Status().Update(ctx, snapshot)
+		})).To(Succeed())
+		Expect(client.Delete(ctx, snapshot)).To(Succeed())
+
+		Eventually(func() bool {
+			err := client.Get(ctx, types.NamespacedName{Name: "stopped", Namespace: defaultNamespace}, snapshot)
+			return apierrors.IsNotFound(err)
+		}, 5).Should(BeTrue())
+	})
+
+	It("provisions the PersistentVolumeClaims of a new RabbitmqCluster from a RabbitmqSnapshot", func() {
+		snapshot := &rabbitmqv1beta1.RabbitmqSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "before-upgrade",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqSnapshotSpec{
+				RabbitmqClusterReference: rabbitmqv1beta1.RabbitmqClusterReference{Name: "rabbitmq-restored"},
+			},
+		}
+		Expect(client.Create(ctx, snapshot)).To(Succeed())
+		// the snapshot was taken from a RabbitmqCluster that no longer exists
+		Expect(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			snapshot = getSnapshot("before-upgrade")
+			snapshot.Status = rabbitmqv1beta1.RabbitmqSnapshotStatus{
+				Phase:      rabbitmqv1beta1.CompletedRabbitmqSnapshotPhase,
+				Replicas:   1,
+				ReadyToUse: true,
+				VolumeSnapshots: []rabbitmqv1beta1.RabbitmqSnapshotVolume{{
+					Node:                      "rabbitmq-restored-rabbitmq-server-0",
+					PersistentVolumeClaimName: "persistence-rabbitmq-restored-rabbitmq-server-0",
+					VolumeSnapshotName:        "before-upgrade-0",
+					ReadyToUse:                true,
+				}},
+				DefaultUserSecretName: "before-upgrade-default-user",
+			}
+			return client.Status().Update(ctx, snapshot)
+		})).To(Succeed())
+		_, err := createSecret(ctx, "before-upgrade-default-user", defaultNamespace, map[string]string{
+			"username": "original-user",
+			"password": "original-password",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		rabbitmqCluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-restored",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: &one,
+				Persistence: rabbitmqv1beta1.RabbitmqClusterPersistenceSpec{
+					RestoreFrom: &rabbitmqv1beta1.RabbitmqSnapshotReference{Name: "before-upgrade"},
+				},
+			},
+		}
+		Expect(client.Create(ctx, rabbitmqCluster)).To(Succeed())
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		Eventually(func() error {
+			return client.Get(ctx, types.NamespacedName{Name: "persistence-rabbitmq-restored-rabbitmq-server-0", Namespace: defaultNamespace}, pvc)
+		}, 5).Should(Succeed())
+		Expect(pvc.Spec.DataSource).NotTo(BeNil())
+		Expect(pvc.Spec.DataSource.Kind).To(Equal("VolumeSnapshot"))
+		Expect(pvc.Spec.DataSource.Name).To(Equal("before-upgrade-0"))
+
+		adminSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: "rabbitmq-restored-rabbitmq-admin", Namespace: defaultNamespace}, adminSecret)).To(Succeed())
+		Expect(adminSecret.Data).To(HaveKeyWithValue("username", []byte("original-user")))
+		Expect(adminSecret.Data).To(HaveKeyWithValue("password", []byte("original-password")))
+
+		statefulSet(ctx, rabbitmqCluster)
+		Expect(client.Delete(ctx, snapshot)).To(Succeed())
+	})
+})
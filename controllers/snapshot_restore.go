@@ -0,0 +1,116 @@
+/*
+RabbitMQ Cluster Operator
+
+Copyright 2020 VMware, Inc. All Rights Reserved.
+
+This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+
+This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// restorePersistentVolumeClaims - helper function that creates the PersistentVolumeClaims of a new RabbitmqCluster from the RabbitmqSnapshot in spec.persistence.restoreFrom
+// the StatefulSet then uses these claims instead of empty ones. Returns true while the RabbitmqSnapshot is not ready to use.
+func (r *RabbitmqClusterReconciler) restorePersistentVolumeClaims(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, builder *resource.RabbitmqResourceBuilder) (bool, error) {
+	restoreFrom := rmq.Spec.Persistence.RestoreFrom
+	if restoreFrom == nil {
+		return false, nil
+	}
+
+	// claims are only restored for a new RabbitmqCluster
+	err := r.Get(ctx, types.NamespacedName{Name: rmq.ChildResourceName("server"), Namespace: rmq.Namespace}, &appsv1.StatefulSet{})
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	snapshot := &rabbitmqv1beta1.RabbitmqSnapshot{}
+	if err := r.Get(ctx, types.NamespacedName{Name: restoreFrom.Name, Namespace: rmq.Namespace}, snapshot); err != nil {
+		if errors.IsNotFound(err) {
+			return true, r.setRestorePending(ctx, rmq, fmt.Sprintf("RabbitmqSnapshot %s does not exist", restoreFrom.Name))
+		}
+		return false, err
+	}
+	if snapshot.Status.Phase != rabbitmqv1beta1.CompletedRabbitmqSnapshotPhase || !snapshot.Status.ReadyToUse {
+		return true, r.setRestorePending(ctx, rmq, fmt.Sprintf("RabbitmqSnapshot %s is not ready to use", snapshot.Name))
+	}
+
+	pvcs, err := builder.RestoredPersistentVolumeClaims(snapshot)
+	if err != nil {
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "RestoreFailed", err.Error())
+		return false, err
+	}
+
+	if waiting, err := r.restoreAdminSecret(ctx, rmq, snapshot, builder); err != nil || waiting {
+		return waiting, err
+	}
+	for _, pvc := range pvcs {
+		if err := r.Create(ctx, pvc); err != nil && !errors.IsAlreadyExists(err) {
+			return false, fmt.Errorf("cannot create PersistentVolumeClaim %s: %w", pvc.Name, err)
+		}
+	}
+
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "RestoredPersistentVolumeClaims",
+		fmt.Sprintf("Provisioned PersistentVolumeClaims from RabbitmqSnapshot %s", snapshot.Name))
+	return false, nil
+}
+
+// restoreAdminSecret - helper function that creates the default user Secret of the RabbitmqCluster from the copy kept by the RabbitmqSnapshot
+// the users are restored with the data, so new random credentials would not be known to RabbitMQ. RabbitmqSnapshots which kept no copy
+// can only be restored once the default user Secret of the snapshotted RabbitmqCluster has been created again. Returns true while it is missing.
+func (r *RabbitmqClusterReconciler) restoreAdminSecret(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, snapshot *rabbitmqv1beta1.RabbitmqSnapshot, builder *resource.RabbitmqResourceBuilder) (bool, error) {
+	adminSecretName := rmq.ChildResourceName(resource.AdminSecretName)
+	if snapshot.Status.DefaultUserSecretName == "" {
+		err := r.Get(ctx, types.NamespacedName{Name: adminSecretName, Namespace: rmq.Namespace}, &corev1.Secret{})
+		if errors.IsNotFound(err) {
+			return true, r.setRestorePending(ctx, rmq, fmt.Sprintf(
+				"RabbitmqSnapshot %s holds no copy of the default user; create the Secret %s of the snapshotted RabbitmqCluster again", snapshot.Name, adminSecretName))
+		}
+		return false, err
+	}
+
+	defaultUser := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: snapshot.Status.DefaultUserSecretName, Namespace: snapshot.Namespace}, defaultUser); err != nil {
+		if errors.IsNotFound(err) {
+			return true, r.setRestorePending(ctx, rmq, fmt.Sprintf("Secret %s of RabbitmqSnapshot %s does not exist", snapshot.Status.DefaultUserSecretName, snapshot.Name))
+		}
+		return false, err
+	}
+
+	secret, err := builder.RestoredAdminSecret(defaultUser)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Create(ctx, secret); err != nil && !errors.IsAlreadyExists(err) {
+		return false, fmt.Errorf("cannot create Secret %s: %w", secret.Name, err)
+	}
+	return false, nil
+}
+
+// setRestorePending - helper function that records why the restore is waiting in the ReconcileSuccess condition
+// the RestorePending event is only emitted when the reason changes, rather than on every requeue
+func (r *RabbitmqClusterReconciler) setRestorePending(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, msg string) error {
+	for _, condition := range rmq.Status.Conditions {
+		if condition.Type == status.ReconcileSuccess && condition.Reason == "RestorePending" && condition.Message == msg {
+			return nil
+		}
+	}
+
+	r.Log.Info(msg, "namespace", rmq.Namespace, "name", rmq.Name)
+	r.Recorder.Event(rmq, corev1.EventTypeWarning, "RestorePending", msg)
+	rmq.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "RestorePending", msg)
+	return r.Status().Update(ctx, rmq)
+}
@@ -0,0 +1,119 @@
+/*
+RabbitMQ Cluster Operator
+
+Copyright 2020 VMware, Inc. All Rights Reserved.
+
+This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+
+This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// optionalKinds are the kinds of the child resources which are only built for some RabbitmqCluster specs,
+// e.g. the per-pod Services of removed StatefulSet ordinals or the ServiceMonitor of a cluster whose monitoring was disabled
+var optionalKinds = []schema.GroupVersionKind{
+	corev1.SchemeGroupVersion.WithKind("Service"),
+	networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"),
+	policyv1beta1.SchemeGroupVersion.WithKind("PodDisruptionBudget"),
+	networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"),
+	resource.GatewayGroupVersion.WithKind(resource.HTTPRouteKind),
+	resource.MonitoringGroupVersion.WithKind(resource.ServiceMonitorKind),
+	resource.MonitoringGroupVersion.WithKind(resource.PodMonitorKind),
+	resource.MonitoringGroupVersion.WithKind(resource.PrometheusRuleKind),
+}
+
+// deleteStaleResources - helper function that deletes the child resources of the optional kinds which are controlled by
+// the RabbitmqCluster but no longer built. Kinds whose CRDs are not installed are skipped
+func (r *RabbitmqClusterReconciler) deleteStaleResources(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, builders []resource.ResourceBuilder) error {
+	desired := map[schema.GroupVersionKind]map[string]bool{}
+	for _, builder := range builders {
+		obj, err := builder.Build()
+		if err != nil {
+			return err
+		}
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			return err
+		}
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		if desired[gvk] == nil {
+			desired[gvk] = map[string]bool{}
+		}
+		desired[gvk][objMeta.GetName()] = true
+	}
+
+	for _, gvk := range optionalKinds {
+		list := r.newList(gvk)
+		err := r.List(ctx, list, client.InNamespace(rmq.Namespace), client.MatchingLabels(metadata.LabelSelector(rmq.Name)))
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			objMeta, err := meta.Accessor(item)
+			if err != nil {
+				return err
+			}
+			if desired[gvk][objMeta.GetName()] || !metav1.IsControlledBy(objMeta, rmq) {
+				continue
+			}
+			if err := r.Delete(ctx, item.(client.Object)); client.IgnoreNotFound(err) != nil {
+				r.Log.Error(err, "Failed to delete stale resource",
+					"namespace", rmq.Namespace,
+					"name", rmq.Name,
+					"kind", gvk.Kind,
+					"resource", objMeta.GetName())
+				return err
+			}
+			r.Log.Info("Deleted stale resource",
+				"namespace", rmq.Namespace,
+				"name", rmq.Name,
+				"kind", gvk.Kind,
+				"resource", objMeta.GetName())
+		}
+	}
+	return nil
+}
+
+// newList - helper function that returns an empty list of the given kind: a typed list, served from the cache, for the
+// kinds registered in the scheme, and an unstructured list otherwise
+func (r *RabbitmqClusterReconciler) newList(gvk schema.GroupVersionKind) client.ObjectList {
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	if obj, err := r.Scheme.New(listGVK); err == nil {
+		if list, ok := obj.(client.ObjectList); ok {
+			return list
+		}
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(listGVK)
+	return list
+}
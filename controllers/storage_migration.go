@@ -0,0 +1,328 @@
+/*
+RabbitMQ Cluster Operator
+
+Copyright 2020 VMware, Inc. All Rights Reserved.
+
+This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+
+This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	storageMigrationRequeueInterval = 10 * time.Second
+	// a node is only drained once the quorum queues it hosts keep a majority of their replicas online without it
+	minStorageMigrationReplicas int32 = 3
+	// annotations marking the default StorageClass of the Kubernetes cluster
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// startStorageMigration - helper function that recreates the StatefulSet when spec.persistence.storageClassName changed
+// volumeClaimTemplates are immutable, so the StatefulSet is deleted with orphan cascading; its Pods and PersistentVolumeClaims
+// are kept and adopted by the StatefulSet created in the next reconciliation. Returns true while the StatefulSet is being deleted.
+func (r *RabbitmqClusterReconciler) startStorageMigration(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (bool, error) {
+	if !rmq.PersistenceEnabled() || volumeClaimTemplatesOverridden(rmq) {
+		return false, nil
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rmq.ChildResourceName("server"), Namespace: rmq.Namespace}, sts); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if !sts.DeletionTimestamp.IsZero() {
+		// the garbage collector has not finished orphaning the Pods yet
+		return true, nil
+	}
+
+	current, ok := persistenceStorageClassName(sts)
+	target := storageClassName(rmq.Spec.Persistence.StorageClassName)
+	if !ok || current == target {
+		return false, nil
+	}
+
+	if migration := rmq.Status.StorageMigration; migration != nil && migration.CurrentNode != "" {
+		// finish moving the current node before moving to yet another StorageClass
+		return false, nil
+	}
+
+	if rmq.Spec.Replicas == nil || *rmq.Spec.Replicas < minStorageMigrationReplicas {
+		msg := fmt.Sprintf("Cannot move a RabbitmqCluster of fewer than %d nodes from StorageClass %q to %q without losing quorum queue availability; scale it out first",
+			minStorageMigrationReplicas, current, target)
+		// the condition is reported until the spec changes again; the event only once
+		rmq.Status.SetCondition(status.NoWarnings, corev1.ConditionFalse, "StorageMigrationUnsupported", msg)
+		if rmq.Status.ObservedGeneration != rmq.Generation {
+			r.Log.Info(msg, "namespace", rmq.Namespace, "name", rmq.Name)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "StorageMigrationUnsupported", msg)
+		}
+		return false, nil
+	}
+
+	if err := r.Delete(ctx, sts, client.PropagationPolicy(metav1.DeletePropagationOrphan)); client.IgnoreNotFound(err) != nil {
+		return false, fmt.Errorf("cannot delete StatefulSet %s to change its StorageClass: %w", sts.Name, err)
+	}
+
+	rmq.Status.StorageMigration = &rabbitmqv1beta1.RabbitmqClusterStorageMigrationStatus{StorageClassName: target}
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		return false, err
+	}
+
+	msg := fmt.Sprintf("Moving RabbitMQ nodes from StorageClass %q to %q", current, target)
+	r.Log.Info(msg, "namespace", rmq.Namespace, "name", rmq.Name)
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "StorageMigrationStarted", msg)
+	return true, nil
+}
+
+// migrateStorage - helper function that moves the nodes to PersistentVolumes of the new StorageClass, one node at a time
+// each node is drained, forgotten by the cluster, and recreated with an empty PersistentVolume; it then rejoins the cluster
+// and the next node is only moved once the quorum queues no longer depend on it. Returns a non-zero result while a migration is in progress.
+func (r *RabbitmqClusterReconciler) migrateStorage(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (ctrl.Result, error) {
+	migration := rmq.Status.StorageMigration
+	if migration == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if migration.CurrentNode == "" {
+		if ready, err := r.allReplicasReady(ctx, rmq); !ready {
+			return ctrl.Result{RequeueAfter: storageMigrationRequeueInterval}, err
+		}
+
+		podName, err := r.nextNodeToMigrate(ctx, rmq)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if podName == "" {
+			rmq.Status.StorageMigration = nil
+			if err := r.Status().Update(ctx, rmq); err != nil {
+				return ctrl.Result{}, err
+			}
+			msg := fmt.Sprintf("Moved all RabbitMQ nodes to StorageClass %q", migration.StorageClassName)
+			r.Log.Info(msg, "namespace", rmq.Namespace, "name", rmq.Name)
+			r.Recorder.Event(rmq, corev1.EventTypeNormal, "StorageMigrationCompleted", msg)
+			return ctrl.Result{}, nil
+		}
+
+		migration.CurrentNode = podName
+		migration.Step = rabbitmqv1beta1.DrainStorageMigrationStep
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	podName := migration.CurrentNode
+	done, err := r.runStorageMigrationStep(ctx, rmq, podName, migration.Step)
+	if err != nil {
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "StorageMigrationFailed",
+			fmt.Sprintf("Failed to move node %s at step %s: %s", podName, migration.Step, err))
+		return ctrl.Result{}, err
+	}
+	if !done {
+		return ctrl.Result{RequeueAfter: storageMigrationRequeueInterval}, nil
+	}
+
+	switch migration.Step {
+	case rabbitmqv1beta1.DrainStorageMigrationStep:
+		migration.Step = rabbitmqv1beta1.ForgetStorageMigrationStep
+	case rabbitmqv1beta1.ForgetStorageMigrationStep:
+		migration.Step = rabbitmqv1beta1.ReplaceStorageMigrationStep
+	case rabbitmqv1beta1.ReplaceStorageMigrationStep:
+		migration.Step = rabbitmqv1beta1.RejoinStorageMigrationStep
+	case rabbitmqv1beta1.RejoinStorageMigrationStep:
+		migration.Step = rabbitmqv1beta1.SyncStorageMigrationStep
+	default:
+		migration.MigratedNodes = append(migration.MigratedNodes, podName)
+		migration.CurrentNode = ""
+		migration.Step = ""
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "StorageMigrationProgressing",
+			fmt.Sprintf("Moved node %s to StorageClass %q", podName, migration.StorageClassName))
+	}
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// runStorageMigrationStep - helper function that runs a single step of moving a node to a new PersistentVolume
+// steps waiting for Kubernetes or RabbitMQ return false until they are done
+func (r *RabbitmqClusterReconciler) runStorageMigrationStep(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName string, step rabbitmqv1beta1.StorageMigrationStep) (bool, error) {
+	nodeName := rabbitmqNodeName(rmq, podName)
+	pvcName := resource.PersistenceClaimName(podName)
+
+	switch step {
+	case rabbitmqv1beta1.DrainStorageMigrationStep:
+		if err := r.execRabbitmqCommand(rmq, podName, "rabbitmq-queues check_if_node_is_quorum_critical"); err != nil {
+			// removing the node would make quorum queues unavailable
+			return false, nil
+		}
+		return true, r.execRabbitmqCommand(rmq, podName, "rabbitmq-upgrade drain")
+
+	case rabbitmqv1beta1.ForgetStorageMigrationStep:
+		peer := r.peerNode(rmq, podName)
+		if peer == "" {
+			return false, fmt.Errorf("no other node to remove %s from the cluster", nodeName)
+		}
+		if err := r.execRabbitmqCommand(rmq, podName, "rabbitmqctl stop_app"); err != nil {
+			return false, err
+		}
+		return true, r.execRabbitmqCommand(rmq, peer, fmt.Sprintf("rabbitmqctl forget_cluster_node %s", nodeName))
+
+	case rabbitmqv1beta1.ReplaceStorageMigrationStep:
+		// the claim is only removed once the Pod using it is gone
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: rmq.Namespace}}
+		if err := r.Delete(ctx, pvc); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("cannot delete PersistentVolumeClaim %s: %w", pvcName, err)
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: rmq.Namespace}}
+		if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("cannot delete Pod %s: %w", podName, err)
+		}
+		return true, nil
+
+	case rabbitmqv1beta1.RejoinStorageMigrationStep:
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: rmq.Namespace}, pvc)
+		if client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		if errors.IsNotFound(err) {
+			// the StatefulSet controller creates the new claim with the Pod
+			return false, nil
+		}
+		if !pvc.DeletionTimestamp.IsZero() {
+			// a Pod recreated before the old claim was removed keeps it in use; delete it again
+			pod := &corev1.Pod{}
+			if err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: rmq.Namespace}, pod); err != nil {
+				return false, client.IgnoreNotFound(err)
+			}
+			if pod.DeletionTimestamp.IsZero() {
+				if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+					return false, fmt.Errorf("cannot delete Pod %s: %w", podName, err)
+				}
+			}
+			return false, nil
+		}
+		if ready, err := r.allReplicasReady(ctx, rmq); !ready {
+			return false, err
+		}
+		return true, r.execRabbitmqCommand(rmq, podName, fmt.Sprintf("rabbitmq-queues grow %s all", nodeName))
+
+	case rabbitmqv1beta1.SyncStorageMigrationStep:
+		return r.execRabbitmqCommand(rmq, podName, "rabbitmq-queues check_if_node_is_quorum_critical") == nil, nil
+	}
+
+	return false, fmt.Errorf("unknown storage migration step %q", step)
+}
+
+// nextNodeToMigrate - helper function that returns the Pod with the highest ordinal not yet moved to the new StorageClass
+func (r *RabbitmqClusterReconciler) nextNodeToMigrate(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (string, error) {
+	migration := rmq.Status.StorageMigration
+	target, err := r.resolveStorageClassName(ctx, migration.StorageClassName)
+	if err != nil {
+		return "", err
+	}
+	migrated := make(map[string]bool, len(migration.MigratedNodes))
+	for _, podName := range migration.MigratedNodes {
+		migrated[podName] = true
+	}
+
+	for i := *rmq.Spec.Replicas - 1; i >= 0; i-- {
+		podName := fmt.Sprintf("%s-%d", rmq.ChildResourceName("server"), i)
+		if migrated[podName] {
+			continue
+		}
+
+		// nodes added after the StatefulSet was recreated already claimed a volume of the new StorageClass
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Name: resource.PersistenceClaimName(podName), Namespace: rmq.Namespace}, pvc); err != nil {
+			return "", err
+		}
+		if target != "" && storageClassName(pvc.Spec.StorageClassName) == target {
+			continue
+		}
+		return podName, nil
+	}
+	return "", nil
+}
+
+// resolveStorageClassName - helper function that returns the name of the default StorageClass for the empty name,
+// which claims without a StorageClass are given by the API server; the empty name is returned when there is no default StorageClass
+func (r *RabbitmqClusterReconciler) resolveStorageClassName(ctx context.Context, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	storageClasses, err := r.Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("cannot find the default StorageClass: %w", err)
+	}
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Annotations[defaultStorageClassAnnotation] == "true" || storageClass.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			return storageClass.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// peerNode returns a Pod other than podName to run cluster-wide commands on
+func (r *RabbitmqClusterReconciler) peerNode(rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) string {
+	for i := int32(0); i < *rmq.Spec.Replicas; i++ {
+		peer := fmt.Sprintf("%s-%d", rmq.ChildResourceName("server"), i)
+		if peer != podName {
+			return peer
+		}
+	}
+	return ""
+}
+
+func (r *RabbitmqClusterReconciler) execRabbitmqCommand(rmq *rabbitmqv1beta1.RabbitmqCluster, podName, command string) error {
+	stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", command)
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf(
+			"Failed to run command %s on pod %s in namespace %s with output: %s %s",
+			command, podName, rmq.Namespace, stdout, stderr))
+	}
+	return err
+}
+
+func rabbitmqNodeName(rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) string {
+	return fmt.Sprintf("rabbit@%s.%s.%s", podName, rmq.ChildResourceName("headless"), rmq.Namespace)
+}
+
+// persistenceStorageClassName returns the StorageClass of the "persistence" volume claim template of sts, if it has one
+func persistenceStorageClassName(sts *appsv1.StatefulSet) (string, bool) {
+	for _, claim := range sts.Spec.VolumeClaimTemplates {
+		if claim.Name == "persistence" {
+			return storageClassName(claim.Spec.StorageClassName), true
+		}
+	}
+	return "", false
+}
+
+func storageClassName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
+
+func volumeClaimTemplatesOverridden(rmq *rabbitmqv1beta1.RabbitmqCluster) bool {
+	override := rmq.Spec.Override.StatefulSet
+	return override != nil && override.Spec != nil && len(override.Spec.VolumeClaimTemplates) != 0
+}
@@ -11,6 +11,7 @@ This product may include a number of subcomponents with separate copyright notic
 package controllers_test
 
 import (
+	"context"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -42,7 +43,7 @@ var (
 	testEnv    *envtest.Environment
 	client     runtimeClient.Client
 	clientSet  *kubernetes.Clientset
-	stopMgr    chan struct{}
+	stopMgr    context.CancelFunc
 	mgrStopped *sync.WaitGroup
 	scheme     *runtime.Scheme
 )
@@ -61,7 +62,12 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "config", "crd", "bases"),
+			// VolumeSnapshot CRD of the CSI external snapshotter, whose controller is faked by the tests,
+			// and the Prometheus Operator and Gateway API CRDs of the optional child resources
+			filepath.Join("testdata", "crds"),
+		},
 	}
 
 	cfg, err = testEnv.Start()
@@ -79,7 +85,7 @@ var _ = BeforeSuite(func() {
 })
 
 var _ = AfterSuite(func() {
-	close(stopMgr)
+	stopMgr()
 	mgrStopped.Wait()
 	By("tearing down the test environment")
 	err := testEnv.Stop()
@@ -92,19 +98,29 @@ func startManager(scheme *runtime.Scheme) {
 	client = mgr.GetClient()
 
 	reconciler := &controllers.RabbitmqClusterReconciler{
-		Client:    client,
-		Log:       ctrl.Log.WithName(controllerName),
-		Scheme:    mgr.GetScheme(),
-		Recorder:  mgr.GetEventRecorderFor(controllerName),
-		Namespace: "rabbitmq-system",
+		Client:              client,
+		Log:                 ctrl.Log.WithName(controllerName),
+		Scheme:              mgr.GetScheme(),
+		Recorder:            mgr.GetEventRecorderFor(controllerName),
+		Namespace:           "rabbitmq-system",
+		SetupContainerImage: "rabbitmq-cluster-operator:latest",
 	}
 	Expect(reconciler.SetupWithManager(mgr)).To(Succeed())
 
-	stopMgr = make(chan struct{})
+	snapshotReconciler := &controllers.RabbitmqSnapshotReconciler{
+		Client:   client,
+		Log:      ctrl.Log.WithName("rabbitmqsnapshot-controller"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("rabbitmqsnapshot-controller"),
+	}
+	Expect(snapshotReconciler.SetupWithManager(mgr)).To(Succeed())
+
+	var mgrCtx context.Context
+	mgrCtx, stopMgr = context.WithCancel(context.Background())
 	mgrStopped = &sync.WaitGroup{}
 	mgrStopped.Add(1)
 	go func() {
 		defer mgrStopped.Done()
-		Expect(mgr.Start(stopMgr)).To(Succeed())
+		Expect(mgr.Start(mgrCtx)).To(Succeed())
 	}()
 }
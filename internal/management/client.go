@@ -0,0 +1,123 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	Port           = 15672
+	defaultTimeout = 10 * time.Second
+)
+
+// Client queries the HTTP API exposed by the rabbitmq_management plugin.
+type Client struct {
+	BaseURL    string
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+}
+
+func NewClient(baseURL, username, password string) *Client {
+	return &Client{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Username: username,
+		Password: password,
+		HTTPClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+}
+
+// Node is the subset of a /api/nodes entry the operator cares about.
+type Node struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	// Uptime in milliseconds
+	Uptime int64 `json:"uptime"`
+	// Names of the nodes this node cannot communicate with
+	Partitions    []string `json:"partitions"`
+	MemAlarm      bool     `json:"mem_alarm"`
+	DiskFreeAlarm bool     `json:"disk_free_alarm"`
+}
+
+// Overview is the subset of /api/overview the operator cares about.
+type Overview struct {
+	ClusterName     string       `json:"cluster_name"`
+	RabbitmqVersion string       `json:"rabbitmq_version"`
+	ErlangVersion   string       `json:"erlang_version"`
+	ObjectTotals    ObjectTotals `json:"object_totals"`
+	QueueTotals     QueueTotals  `json:"queue_totals"`
+}
+
+type ObjectTotals struct {
+	Connections int64 `json:"connections"`
+	Channels    int64 `json:"channels"`
+	Queues      int64 `json:"queues"`
+	Consumers   int64 `json:"consumers"`
+}
+
+type QueueTotals struct {
+	Messages               int64 `json:"messages"`
+	MessagesReady          int64 `json:"messages_ready"`
+	MessagesUnacknowledged int64 `json:"messages_unacknowledged"`
+}
+
+func (c *Client) Nodes(ctx context.Context) ([]Node, error) {
+	var nodes []Node
+	if err := c.get(ctx, "/api/nodes", &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (c *Client) Overview(ctx context.Context) (*Overview, error) {
+	overview := &Overview{}
+	if err := c.get(ctx, "/api/overview", overview); err != nil {
+		return nil, err
+	}
+	return overview, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, into interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s: %s", resp.StatusCode, path, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, into); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", path, err)
+	}
+	return nil
+}
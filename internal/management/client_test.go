@@ -0,0 +1,120 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package management_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/management"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server       *httptest.Server
+		client       *management.Client
+		responseCode int
+		responseBody string
+		requestPath  string
+		username     string
+		password     string
+	)
+
+	BeforeEach(func() {
+		responseCode = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestPath = r.URL.Path
+			username, password, _ = r.BasicAuth()
+			w.WriteHeader(responseCode)
+			_, _ = w.Write([]byte(responseBody))
+		}))
+		client = management.NewClient(server.URL+"/", "guest", "secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Nodes", func() {
+		BeforeEach(func() {
+			responseBody = `[
+				{"name": "rabbit@node-0", "running": true, "uptime": 12000, "type": "disc", "partitions": ["rabbit@node-1"], "mem_alarm": true, "disk_free_alarm": false},
+				{"name": "rabbit@node-1", "running": false, "partitions": [], "disk_free_alarm": true}
+			]`
+		})
+
+		It("queries /api/nodes with basic auth", func() {
+			_, err := client.Nodes(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestPath).To(Equal("/api/nodes"))
+			Expect(username).To(Equal("guest"))
+			Expect(password).To(Equal("secret"))
+		})
+
+		It("decodes the nodes", func() {
+			nodes, err := client.Nodes(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nodes).To(ConsistOf(
+				management.Node{Name: "rabbit@node-0", Running: true, Uptime: 12000, Partitions: []string{"rabbit@node-1"}, MemAlarm: true},
+				management.Node{Name: "rabbit@node-1", Running: false, Partitions: []string{}, DiskFreeAlarm: true},
+			))
+		})
+	})
+
+	Context("Overview", func() {
+		BeforeEach(func() {
+			responseBody = `{
+				"cluster_name": "my-cluster",
+				"rabbitmq_version": "3.8.9",
+				"erlang_version": "23.1",
+				"object_totals": {"connections": 3, "channels": 6, "queues": 2, "consumers": 4},
+				"queue_totals": {"messages": 10, "messages_ready": 7, "messages_unacknowledged": 3}
+			}`
+		})
+
+		It("queries /api/overview and decodes the response", func() {
+			overview, err := client.Overview(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestPath).To(Equal("/api/overview"))
+			Expect(overview.ClusterName).To(Equal("my-cluster"))
+			Expect(overview.RabbitmqVersion).To(Equal("3.8.9"))
+			Expect(overview.ErlangVersion).To(Equal("23.1"))
+			Expect(overview.ObjectTotals).To(Equal(management.ObjectTotals{Connections: 3, Channels: 6, Queues: 2, Consumers: 4}))
+			Expect(overview.QueueTotals).To(Equal(management.QueueTotals{Messages: 10, MessagesReady: 7, MessagesUnacknowledged: 3}))
+		})
+	})
+
+	When("the API returns an error", func() {
+		BeforeEach(func() {
+			responseCode = http.StatusUnauthorized
+			responseBody = `{"error":"not_authorised","reason":"Login failed"}`
+		})
+
+		It("returns an error containing the status and body", func() {
+			_, err := client.Overview(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("unexpected status 401")))
+			Expect(err).To(MatchError(ContainSubstring("Login failed")))
+		})
+	})
+
+	When("the response cannot be decoded", func() {
+		BeforeEach(func() {
+			responseBody = `not-json`
+		})
+
+		It("returns an error", func() {
+			_, err := client.Nodes(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("failed to decode response from /api/nodes")))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "rabbitmq_cluster_operator"
+
+var (
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to reconcile a RabbitmqCluster.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"namespace", "name"})
+
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of reconciliations of a RabbitmqCluster that returned an error.",
+	}, []string{"namespace", "name"})
+
+	PluginEnableFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plugin_enable_failures_total",
+		Help:      "Number of failed attempts to enable plugins on a RabbitMQ Pod.",
+	}, []string{"namespace", "name"})
+
+	RollingRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rolling_restarts_total",
+		Help:      "Number of rolling restarts of the StatefulSet triggered by configuration changes.",
+	}, []string{"namespace", "name"})
+
+	TLSSecretValidationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tls_secret_validation_failures_total",
+		Help:      "Number of times the TLS secrets of a RabbitmqCluster were missing or invalid.",
+	}, []string{"namespace", "name"})
+
+	CertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tls_certificate_expiry_timestamp_seconds",
+		Help:      "Expiry time of the TLS certificate of a RabbitmqCluster, in seconds since the Unix epoch.",
+	}, []string{"namespace", "name", "secret"})
+
+	managedClustersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "managed_clusters"),
+		"Number of RabbitmqClusters managed by the operator, by phase.",
+		[]string{"phase"}, nil,
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		ReconcileDuration,
+		ReconcileErrors,
+		PluginEnableFailures,
+		RollingRestarts,
+		TLSSecretValidationFailures,
+		CertificateExpiry,
+	)
+}
+
+// ObserveReconcile records the duration and outcome of a reconciliation.
+func ObserveReconcile(namespace, name string, duration time.Duration, err error) {
+	ReconcileDuration.WithLabelValues(namespace, name).Observe(duration.Seconds())
+	if err != nil {
+		ReconcileErrors.WithLabelValues(namespace, name).Inc()
+	}
+}
+
+// ForgetCluster removes every series of a deleted RabbitmqCluster.
+func ForgetCluster(cluster *rabbitmqv1beta1.RabbitmqCluster) {
+	ReconcileDuration.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	ReconcileErrors.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	PluginEnableFailures.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	RollingRestarts.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	TLSSecretValidationFailures.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	CertificateExpiry.DeleteLabelValues(cluster.Namespace, cluster.Name, cluster.Spec.TLS.SecretName)
+}
+
+// ManagedClustersCollector counts RabbitmqClusters by phase each time metrics are scraped.
+type ManagedClustersCollector struct {
+	Reader client.Reader
+}
+
+func (c *ManagedClustersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedClustersDesc
+}
+
+func (c *ManagedClustersCollector) Collect(ch chan<- prometheus.Metric) {
+	clusters := &rabbitmqv1beta1.RabbitmqClusterList{}
+	if err := c.Reader.List(context.Background(), clusters); err != nil {
+		ch <- prometheus.NewInvalidMetric(managedClustersDesc, err)
+		return
+	}
+
+	phases := map[string]int{}
+	for _, cluster := range clusters.Items {
+		phases[cluster.Status.ClusterStatus]++
+	}
+	for phase, count := range phases {
+		ch <- prometheus.MustNewConstMetric(managedClustersDesc, prometheus.GaugeValue, float64(count), phase)
+	}
+}
+
+// RegisterManagedClustersCollector registers a ManagedClustersCollector listing RabbitmqClusters from the given reader.
+func RegisterManagedClustersCollector(reader client.Reader) error {
+	if err := ctrlmetrics.Registry.Register(&ManagedClustersCollector{Reader: reader}); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			return err
+		}
+	}
+	return nil
+}
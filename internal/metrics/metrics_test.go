@@ -0,0 +1,80 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package metrics_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metrics", func() {
+	Context("ObserveReconcile", func() {
+		It("counts reconciliations that returned an error", func() {
+			metrics.ObserveReconcile("ns", "observe", time.Second, nil)
+			Expect(testutil.ToFloat64(metrics.ReconcileErrors.WithLabelValues("ns", "observe"))).To(Equal(0.0))
+
+			metrics.ObserveReconcile("ns", "observe", time.Second, errors.New("boom"))
+			Expect(testutil.ToFloat64(metrics.ReconcileErrors.WithLabelValues("ns", "observe"))).To(Equal(1.0))
+		})
+	})
+
+	Context("ForgetCluster", func() {
+		It("removes the series of the deleted cluster", func() {
+			cluster := &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "forget", Namespace: "ns"},
+			}
+			cluster.Spec.TLS.SecretName = "tls-secret"
+			metrics.RollingRestarts.WithLabelValues("ns", "forget").Inc()
+			metrics.CertificateExpiry.WithLabelValues("ns", "forget", "tls-secret").Set(42)
+
+			metrics.ForgetCluster(cluster)
+
+			Expect(metrics.RollingRestarts.DeleteLabelValues("ns", "forget")).To(BeFalse())
+			Expect(metrics.CertificateExpiry.DeleteLabelValues("ns", "forget", "tls-secret")).To(BeFalse())
+		})
+	})
+
+	Context("ManagedClustersCollector", func() {
+		It("counts clusters by phase", func() {
+			scheme := runtime.NewScheme()
+			Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+			clusterInPhase := func(name, phase string) runtime.Object {
+				cluster := &rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+				}
+				cluster.Status.ClusterStatus = phase
+				return cluster
+			}
+			reader := fake.NewFakeClientWithScheme(scheme,
+				clusterInPhase("a", "Running"),
+				clusterInPhase("b", "Running"),
+				clusterInPhase("c", "Degraded"),
+			)
+
+			expected := `
+# HELP rabbitmq_cluster_operator_managed_clusters Number of RabbitmqClusters managed by the operator, by phase.
+# TYPE rabbitmq_cluster_operator_managed_clusters gauge
+rabbitmq_cluster_operator_managed_clusters{phase="Degraded"} 1
+rabbitmq_cluster_operator_managed_clusters{phase="Running"} 2
+`
+			Expect(testutil.CollectAndCompare(&metrics.ManagedClustersCollector{Reader: reader}, strings.NewReader(expected))).To(Succeed())
+		})
+	})
+})
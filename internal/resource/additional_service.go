@@ -0,0 +1,124 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reservedServiceNames are the names of the Services created by the operator itself
+var reservedServiceNames = []string{"client", headlessServiceName, managementServiceName}
+
+// AdditionalServiceBuilder builds a Service from spec.additionalServices, exposing a subset of the client Service ports.
+type AdditionalServiceBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+	Spec     rabbitmqv1beta1.RabbitmqClusterAdditionalServiceSpec
+}
+
+func (builder *RabbitmqResourceBuilder) AdditionalService(spec rabbitmqv1beta1.RabbitmqClusterAdditionalServiceSpec) *AdditionalServiceBuilder {
+	return &AdditionalServiceBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+		Spec:     spec,
+	}
+}
+
+// validateAdditionalServices checks that the names of spec.additionalServices are unique and do not clash with the Services created by the operator
+func validateAdditionalServices(instance *rabbitmqv1beta1.RabbitmqCluster) error {
+	names := map[string]bool{}
+	for _, service := range instance.Spec.AdditionalServices {
+		if names[service.Name] {
+			return fmt.Errorf("spec.additionalServices: duplicate name %q", service.Name)
+		}
+		names[service.Name] = true
+
+		if strings.HasPrefix(service.Name, "server") {
+			return fmt.Errorf("spec.additionalServices: name %q is reserved", service.Name)
+		}
+		for _, reserved := range reservedServiceNames {
+			if service.Name == reserved {
+				return fmt.Errorf("spec.additionalServices: name %q is reserved", service.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (builder *AdditionalServiceBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *AdditionalServiceBuilder) Build() (runtime.Object, error) {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(builder.Spec.Name),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *AdditionalServiceBuilder) Update(object runtime.Object) error {
+	service := object.(*corev1.Service)
+	service.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	service.Annotations = metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(service.Annotations, builder.Instance.Annotations), builder.Spec.Annotations)
+
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	if builder.Spec.Type != "" {
+		service.Spec.Type = builder.Spec.Type
+	}
+	service.Spec.Selector = metadata.LabelSelector(builder.Instance.Name)
+
+	ports, err := builder.selectPorts(service.Spec.Ports)
+	if err != nil {
+		return err
+	}
+	service.Spec.Ports = ports
+
+	if service.Spec.Type == corev1.ServiceTypeClusterIP {
+		for i := range service.Spec.Ports {
+			service.Spec.Ports[i].NodePort = int32(0)
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, service, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
+
+// selectPorts returns the client Service ports named in the spec, in the order of the spec, keeping allocated NodePorts
+// the Prometheus port is only exposed by the client Service
+func (builder *AdditionalServiceBuilder) selectPorts(servicePorts []corev1.ServicePort) ([]corev1.ServicePort, error) {
+	clientServiceBuilder := &ClientServiceBuilder{Instance: builder.Instance}
+	available := map[string]corev1.ServicePort{}
+	for _, port := range clientServiceBuilder.updatePortsWithoutPrometheus(servicePorts) {
+		available[port.Name] = port
+	}
+
+	selected := []corev1.ServicePort{}
+	for _, name := range builder.Spec.Ports {
+		port, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("spec.additionalServices: port %q of Service %q is not exposed by the RabbitmqCluster", name, builder.Spec.Name)
+		}
+		selected = append(selected, port)
+	}
+	return selected, nil
+}
@@ -0,0 +1,141 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("AdditionalService", func() {
+	var (
+		instance       rabbitmqv1beta1.RabbitmqCluster
+		builder        *resource.RabbitmqResourceBuilder
+		serviceBuilder *resource.AdditionalServiceBuilder
+		service        *corev1.Service
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbit",
+				Namespace: "rabbit-namespace",
+			},
+		}
+		instance.Spec.TLS.SecretName = "tls-secret"
+		builder = &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		serviceBuilder = builder.AdditionalService(rabbitmqv1beta1.RabbitmqClusterAdditionalServiceSpec{
+			Name:  "amqps-external",
+			Type:  corev1.ServiceTypeLoadBalancer,
+			Ports: []string{"amqps", "management"},
+			Annotations: map[string]string{
+				"service.beta.kubernetes.io/aws-load-balancer-internal": "false",
+			},
+		})
+		obj, err := serviceBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		service = obj.(*corev1.Service)
+	})
+
+	Context("Build", func() {
+		It("names the Service after the spec", func() {
+			Expect(service.Name).To(Equal("rabbit-rabbitmq-amqps-external"))
+			Expect(service.Namespace).To(Equal("rabbit-namespace"))
+		})
+	})
+
+	Context("Update", func() {
+		It("exposes only the selected ports with the type and annotations of the spec", func() {
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+			Expect(service.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-internal", "false"))
+			Expect(service.Spec.Selector).To(Equal(map[string]string{"app.kubernetes.io/name": "rabbit"}))
+			Expect(service.Spec.Ports).To(Equal([]corev1.ServicePort{
+				{Name: "amqps", Protocol: corev1.ProtocolTCP, Port: 5671},
+				{Name: "management", Protocol: corev1.ProtocolTCP, Port: 15672},
+			}))
+			Expect(service.OwnerReferences).To(HaveLen(1))
+		})
+
+		It("keeps allocated NodePorts", func() {
+			service.Spec.Ports = []corev1.ServicePort{{Name: "amqps", Protocol: corev1.ProtocolTCP, Port: 5671, NodePort: 30001}}
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Spec.Ports[0].NodePort).To(Equal(int32(30001)))
+		})
+
+		It("defaults to a ClusterIP Service without NodePorts", func() {
+			serviceBuilder.Spec.Type = ""
+			service.Spec.Ports = []corev1.ServicePort{{Name: "amqps", Protocol: corev1.ProtocolTCP, Port: 5671, NodePort: 30001}}
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(service.Spec.Ports[0].NodePort).To(BeZero())
+		})
+
+		It("errors when a port is not exposed by the RabbitmqCluster", func() {
+			serviceBuilder.Spec.Ports = []string{"mqtt"}
+
+			Expect(serviceBuilder.Update(service)).To(MatchError(ContainSubstring(`port "mqtt" of Service "amqps-external" is not exposed`)))
+		})
+
+		It("does not expose the Prometheus port, which the ServiceMonitor scrapes through the client Service", func() {
+			instance.Spec.Monitoring = &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{MonitorKind: "ServiceMonitor"}
+			serviceBuilder.Spec.Ports = []string{"prometheus"}
+
+			Expect(serviceBuilder.Update(service)).To(MatchError(ContainSubstring(`port "prometheus" of Service "amqps-external" is not exposed`)))
+		})
+	})
+
+	Context("ResourceBuilders", func() {
+		It("rejects reserved names", func() {
+			for _, name := range []string{"client", "headless", "management", "server-0"} {
+				instance.Spec.AdditionalServices = []rabbitmqv1beta1.RabbitmqClusterAdditionalServiceSpec{{Name: name, Ports: []string{"amqp"}}}
+				_, err := builder.ResourceBuilders()
+				Expect(err).To(MatchError(ContainSubstring("is reserved")))
+			}
+		})
+
+		It("rejects duplicate names", func() {
+			instance.Spec.AdditionalServices = []rabbitmqv1beta1.RabbitmqClusterAdditionalServiceSpec{
+				{Name: "amqp", Ports: []string{"amqp"}},
+				{Name: "amqp", Ports: []string{"amqps"}},
+			}
+			_, err := builder.ResourceBuilders()
+			Expect(err).To(MatchError(ContainSubstring(`duplicate name "amqp"`)))
+		})
+
+		It("appends a builder per additional Service", func() {
+			instance.Spec.AdditionalServices = []rabbitmqv1beta1.RabbitmqClusterAdditionalServiceSpec{
+				{Name: "amqp-internal", Ports: []string{"amqp"}},
+				{Name: "amqps-external", Ports: []string{"amqps"}},
+			}
+			resourceBuilders, err := builder.ResourceBuilders()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resourceBuilders).To(HaveLen(12))
+			Expect(resourceBuilders[10].(*resource.AdditionalServiceBuilder).Spec.Name).To(Equal("amqp-internal"))
+			Expect(resourceBuilders[11].(*resource.AdditionalServiceBuilder).Spec.Name).To(Equal("amqps-external"))
+		})
+	})
+})
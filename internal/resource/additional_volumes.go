@@ -0,0 +1,135 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"path"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const additionalVolumesDir = "/var/lib/rabbitmq"
+
+// reservedVolumeNames are the names of the volumes of the RabbitMQ Pods, and of the directories already mounted under /var/lib/rabbitmq
+var reservedVolumeNames = []string{
+	"persistence", "mnesia", "rabbitmq-admin", "server-conf", "plugins-conf", "rabbitmq-etc", "rabbitmq-erlang-cookie",
+	"erlang-cookie-secret", "pod-info", "rabbitmq-tls", "rabbitmq-mutual-tls", communityPluginsVolumeName,
+}
+
+func validateAdditionalVolumes(instance *rabbitmqv1beta1.RabbitmqCluster) error {
+	names := map[string]bool{}
+	for _, name := range reservedVolumeNames {
+		names[name] = true
+	}
+	contents := map[rabbitmqv1beta1.AdditionalVolumeContent]bool{}
+	for _, volume := range instance.Spec.Persistence.AdditionalVolumes {
+		if names[volume.Name] {
+			return fmt.Errorf("spec.persistence.additionalVolumes: name %q is reserved or used more than once", volume.Name)
+		}
+		names[volume.Name] = true
+		if contents[volume.Content] {
+			return fmt.Errorf("spec.persistence.additionalVolumes: only one volume can hold %s", volume.Content)
+		}
+		contents[volume.Content] = true
+	}
+	return nil
+}
+
+func additionalVolumeMountPath(volume rabbitmqv1beta1.RabbitmqClusterAdditionalVolume) string {
+	return path.Join(additionalVolumesDir, volume.Name)
+}
+
+// additionalVolumeWithContent returns the additional volume holding the given RabbitMQ data, if any
+func additionalVolumeWithContent(instance *rabbitmqv1beta1.RabbitmqCluster, content rabbitmqv1beta1.AdditionalVolumeContent) (rabbitmqv1beta1.RabbitmqClusterAdditionalVolume, bool) {
+	for _, volume := range instance.Spec.Persistence.AdditionalVolumes {
+		if volume.Content == content {
+			return volume, true
+		}
+	}
+	return rabbitmqv1beta1.RabbitmqClusterAdditionalVolume{}, false
+}
+
+func additionalVolumeClaims(instance *rabbitmqv1beta1.RabbitmqCluster, scheme *runtime.Scheme) ([]corev1.PersistentVolumeClaim, error) {
+	var pvcs []corev1.PersistentVolumeClaim
+	for _, volume := range instance.Spec.Persistence.AdditionalVolumes {
+		pvc := corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        volume.Name,
+				Namespace:   instance.GetNamespace(),
+				Labels:      metadata.Label(instance.Name),
+				Annotations: metadata.ReconcileAndFilterAnnotations(map[string]string{}, instance.Annotations),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: volume.Storage,
+					},
+				},
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: volume.StorageClassName,
+			},
+		}
+		if err := controllerutil.SetControllerReference(instance, &pvc, scheme); err != nil {
+			return nil, fmt.Errorf("failed setting controller reference: %v", err)
+		}
+		disableBlockOwnerDeletion(pvc)
+		pvcs = append(pvcs, pvc)
+	}
+	return pvcs, nil
+}
+
+// addAdditionalVolumes mounts the additional volumes in the rabbitmq container and points RabbitMQ at them:
+// quorum queue segments through RABBITMQ_QUORUM_DIR, and the classic message store through a msg_stores symlink
+// in the node data directory, created by the setup container on the first start of the Pod; the quorum WAL is configured in rabbitmq.conf
+func (builder *StatefulSetBuilder) addAdditionalVolumes(podSpec *corev1.PodSpec) {
+	volumes := builder.Instance.Spec.Persistence.AdditionalVolumes
+	if len(volumes) == 0 {
+		return
+	}
+
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != "rabbitmq" {
+			continue
+		}
+		for _, volume := range volumes {
+			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      volume.Name,
+				MountPath: additionalVolumeMountPath(volume),
+			})
+		}
+		if volume, ok := additionalVolumeWithContent(builder.Instance, rabbitmqv1beta1.QuorumSegmentsVolumeContent); ok {
+			podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, corev1.EnvVar{
+				Name:  "RABBITMQ_QUORUM_DIR",
+				Value: additionalVolumeMountPath(volume),
+			})
+		}
+	}
+
+	volume, ok := additionalVolumeWithContent(builder.Instance, rabbitmqv1beta1.ClassicMessageStoreVolumeContent)
+	if !ok {
+		return
+	}
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Name != "setup-container" {
+			continue
+		}
+		// the data directory of a node is named after RABBITMQ_NODENAME
+		podSpec.InitContainers[i].Args = append(podSpec.InitContainers[i].Args,
+			fmt.Sprintf("--node-domain=%s.%s", builder.Instance.ChildResourceName(headlessServiceName), builder.Instance.Namespace),
+			"--message-store-dir="+additionalVolumeMountPath(volume),
+		)
+	}
+}
@@ -0,0 +1,119 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("AdditionalVolumes", func() {
+	var (
+		instance     rabbitmqv1beta1.RabbitmqCluster
+		stsBuilder   *resource.StatefulSetBuilder
+		statefulSet  *appsv1.StatefulSet
+		storageClass = "fast-ssd"
+	)
+
+	BeforeEach(func() {
+		instance = generateRabbitmqCluster()
+		instance.Spec.Persistence.AdditionalVolumes = []rabbitmqv1beta1.RabbitmqClusterAdditionalVolume{
+			{Name: "quorum-wal", Storage: k8sresource.MustParse("5Gi"), StorageClassName: &storageClass, Content: rabbitmqv1beta1.QuorumWALVolumeContent},
+			{Name: "quorum-segments", Storage: k8sresource.MustParse("20Gi"), Content: rabbitmqv1beta1.QuorumSegmentsVolumeContent},
+			{Name: "msg-store", Storage: k8sresource.MustParse("20Gi"), Content: rabbitmqv1beta1.ClassicMessageStoreVolumeContent},
+		}
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		stsBuilder = builder.StatefulSet()
+		statefulSet = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instance.Name,
+				Namespace: instance.Namespace,
+			},
+		}
+	})
+
+	It("adds a volume claim template per additional volume", func() {
+		obj, err := stsBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		claims := obj.(*appsv1.StatefulSet).Spec.VolumeClaimTemplates
+
+		Expect(claims).To(HaveLen(4))
+		Expect(claims[0].Name).To(Equal("persistence"))
+		Expect(claims[1].Name).To(Equal("quorum-wal"))
+		Expect(claims[1].Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(k8sresource.MustParse("5Gi")))
+		Expect(claims[1].Spec.StorageClassName).To(Equal(&storageClass))
+		Expect(claims[1].Labels).To(HaveKeyWithValue("app.kubernetes.io/name", instance.Name))
+		Expect(claims[1].OwnerReferences).To(HaveLen(1))
+		Expect(claims[2].Name).To(Equal("quorum-segments"))
+		Expect(claims[3].Name).To(Equal("msg-store"))
+	})
+
+	It("mounts the additional volumes and points RabbitMQ at them", func() {
+		Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+		container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+		Expect(container.VolumeMounts).To(ContainElements(
+			corev1.VolumeMount{Name: "quorum-wal", MountPath: "/var/lib/rabbitmq/quorum-wal"},
+			corev1.VolumeMount{Name: "quorum-segments", MountPath: "/var/lib/rabbitmq/quorum-segments"},
+			corev1.VolumeMount{Name: "msg-store", MountPath: "/var/lib/rabbitmq/msg-store"},
+		))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "RABBITMQ_QUORUM_DIR", Value: "/var/lib/rabbitmq/quorum-segments"}))
+
+		setupContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+		Expect(setupContainer.Args).To(ContainElements(
+			"--node-domain=foo-rabbitmq-headless.foo-namespace",
+			"--message-store-dir=/var/lib/rabbitmq/msg-store",
+		))
+	})
+
+	It("does not link the message store without a ClassicMessageStore volume", func() {
+		instance.Spec.Persistence.AdditionalVolumes = instance.Spec.Persistence.AdditionalVolumes[:1]
+		Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+		setupContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+		Expect(setupContainer.Args).NotTo(ContainElement(HavePrefix("--message-store-dir")))
+		for _, env := range extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq").Env {
+			Expect(env.Name).NotTo(Equal("RABBITMQ_QUORUM_DIR"))
+		}
+	})
+
+	It("rejects reserved names", func() {
+		instance.Spec.Persistence.AdditionalVolumes[0].Name = "rabbitmq-etc"
+
+		Expect(stsBuilder.Update(statefulSet)).To(MatchError(ContainSubstring(`name "rabbitmq-etc" is reserved`)))
+	})
+
+	It("rejects two volumes holding the same data", func() {
+		instance.Spec.Persistence.AdditionalVolumes[1].Content = rabbitmqv1beta1.QuorumWALVolumeContent
+
+		Expect(stsBuilder.Update(statefulSet)).To(MatchError(ContainSubstring("only one volume can hold QuorumWAL")))
+	})
+
+	It("errors when the volumes are added to an existing StatefulSet", func() {
+		statefulSet.ResourceVersion = "1"
+		statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "persistence"}}}
+
+		Expect(stsBuilder.Update(statefulSet)).To(MatchError(ContainSubstring("missing volume claim template quorum-wal")))
+	})
+})
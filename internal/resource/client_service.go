@@ -121,32 +121,18 @@ func (builder *ClientServiceBuilder) updatePorts(servicePorts []corev1.ServicePo
 			Name:     "management",
 		},
 	}
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_mqtt") {
-		servicePortsMap["mqtt"] = corev1.ServicePort{
+	for _, port := range PluginPorts(builder.Instance, false) {
+		servicePortsMap[port.Name] = corev1.ServicePort{
 			Protocol: corev1.ProtocolTCP,
-			Port:     1883,
-			Name:     "mqtt",
+			Port:     port.Port,
+			Name:     port.Name,
 		}
 	}
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_web_mqtt") {
-		servicePortsMap["web-mqtt"] = corev1.ServicePort{
+	if builder.Instance.ServiceMonitorEnabled() {
+		servicePortsMap[prometheusPortName] = corev1.ServicePort{
 			Protocol: corev1.ProtocolTCP,
-			Port:     15675,
-			Name:     "web-mqtt",
-		}
-	}
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_stomp") {
-		servicePortsMap["stomp"] = corev1.ServicePort{
-			Protocol: corev1.ProtocolTCP,
-			Port:     61613,
-			Name:     "stomp",
-		}
-	}
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_web_stomp") {
-		servicePortsMap["web-stomp"] = corev1.ServicePort{
-			Protocol: corev1.ProtocolTCP,
-			Port:     15674,
-			Name:     "web-stomp",
+			Port:     15692,
+			Name:     prometheusPortName,
 		}
 	}
 	if builder.Instance.TLSEnabled() {
@@ -155,6 +141,13 @@ func (builder *ClientServiceBuilder) updatePorts(servicePorts []corev1.ServicePo
 			Port:     5671,
 			Name:     "amqps",
 		}
+		for _, port := range PluginPorts(builder.Instance, true) {
+			servicePortsMap[port.Name] = corev1.ServicePort{
+				Protocol: corev1.ProtocolTCP,
+				Port:     port.Port,
+				Name:     port.Name,
+			}
+		}
 	}
 
 	updatedServicePorts := []corev1.ServicePort{}
@@ -176,6 +169,18 @@ func (builder *ClientServiceBuilder) updatePorts(servicePorts []corev1.ServicePo
 
 }
 
+// updatePortsWithoutPrometheus returns the ports of updatePorts except the Prometheus port, for the other Services selecting RabbitMQ Pods.
+// The ServiceMonitor selects every Service of the RabbitmqCluster exposing that port, so only the client Service does, and each node is scraped once.
+func (builder *ClientServiceBuilder) updatePortsWithoutPrometheus(servicePorts []corev1.ServicePort) []corev1.ServicePort {
+	ports := []corev1.ServicePort{}
+	for _, port := range builder.updatePorts(servicePorts) {
+		if port.Name != prometheusPortName {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
 func (builder *ClientServiceBuilder) setAnnotations(service *corev1.Service) {
 	if builder.Instance.Spec.Service.Annotations != nil {
 		service.Annotations = metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(service.Annotations, builder.Instance.Annotations), builder.Instance.Spec.Service.Annotations)
@@ -319,8 +319,58 @@ var _ = Context("ClientServices", func() {
 				Entry("MQTT-over-WebSockets", "rabbitmq_web_mqtt", "web-mqtt", 15675),
 				Entry("STOMP", "rabbitmq_stomp", "stomp", 61613),
 				Entry("STOMP-over-WebSockets", "rabbitmq_web_stomp", "web-stomp", 15674),
+				Entry("Streams", "rabbitmq_stream", "stream", 5552),
 			)
 
+			It("exposes the ports of community plugins", func() {
+				instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_custom"}
+				instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+					{
+						Name:  "rabbitmq_custom",
+						Ports: []rabbitmqv1beta1.PluginPort{{Name: "custom", Port: 4242}},
+					},
+				}
+				Expect(serviceBuilder.Update(svc)).To(Succeed())
+
+				Expect(svc.Spec.Ports).To(ContainElement(corev1.ServicePort{
+					Name:     "custom",
+					Port:     4242,
+					Protocol: corev1.ProtocolTCP,
+				}))
+			})
+
+			It("exposes the stream TLS port when TLS is enabled", func() {
+				instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_stream"}
+				instance.Spec.TLS.SecretName = "tls-secret"
+				Expect(serviceBuilder.Update(svc)).To(Succeed())
+
+				Expect(svc.Spec.Ports).To(ContainElement(corev1.ServicePort{
+					Name:     "streams",
+					Port:     5551,
+					Protocol: corev1.ProtocolTCP,
+				}))
+			})
+
+			It("exposes the prometheus port when a ServiceMonitor is requested", func() {
+				instance.Spec.Monitoring = &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{MonitorKind: "ServiceMonitor"}
+				Expect(serviceBuilder.Update(svc)).To(Succeed())
+
+				Expect(svc.Spec.Ports).To(ContainElement(corev1.ServicePort{
+					Name:     "prometheus",
+					Port:     15692,
+					Protocol: corev1.ProtocolTCP,
+				}))
+			})
+
+			It("does not expose the prometheus port for a PodMonitor", func() {
+				instance.Spec.Monitoring = &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{MonitorKind: "PodMonitor"}
+				Expect(serviceBuilder.Update(svc)).To(Succeed())
+
+				for _, port := range svc.Spec.Ports {
+					Expect(port.Name).NotTo(Equal("prometheus"))
+				}
+			})
+
 			It("updates the service type from ClusterIP to NodePort", func() {
 				svc.Spec.Type = corev1.ServiceTypeClusterIP
 				serviceBuilder.Instance.Spec.Service.Type = "NodePort"
@@ -0,0 +1,195 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	communityPluginsVolumeName  = "community-plugins"
+	communityPluginsDir         = "/opt/rabbitmq/community-plugins"
+	communityPluginsFetchDir    = "/community-plugins"
+	defaultCommunityPluginsPath = "/plugins"
+	// plugins shipped with the RabbitMQ image
+	defaultPluginsDir = "/opt/rabbitmq/plugins"
+)
+
+var (
+	// characters replaced by "-" in the names of the init containers of community plugins
+	invalidContainerNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+	// PLUGINS_DIR or RABBITMQ_PLUGINS_DIR set in rabbitmq-env.conf
+	pluginsDirEnvConfig = regexp.MustCompile(`(?m)^\s*(export\s+)?(RABBITMQ_)?PLUGINS_DIR\s*=`)
+)
+
+func validateCommunityPlugins(instance *rabbitmqv1beta1.RabbitmqCluster) error {
+	for _, plugin := range instance.FetchedCommunityPlugins() {
+		source := plugin.Source
+		switch {
+		case source.URL != "" && source.Image != "":
+			return fmt.Errorf("community plugin %s: only one of source.url and source.image can be set", plugin.Name)
+		case source.URL == "" && source.Image == "":
+			return fmt.Errorf("community plugin %s: one of source.url and source.image must be set", plugin.Name)
+		case source.URL != "" && source.SHA256 == "":
+			return fmt.Errorf("community plugin %s: source.sha256 is required with source.url", plugin.Name)
+		}
+		if source.URL != "" {
+			if _, err := url.ParseRequestURI(source.URL); err != nil {
+				return fmt.Errorf("community plugin %s: invalid source.url: %w", plugin.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// addCommunityPlugins downloads the community plugins fetched from a URL in the setup-container, adds an init container
+// per community plugin fetched from an image, copying its .ez files into a volume shared with the rabbitmq container,
+// and adds that volume to the plugins directories of RabbitMQ
+func (builder *StatefulSetBuilder) addCommunityPlugins(podSpec *corev1.PodSpec) {
+	plugins := builder.Instance.FetchedCommunityPlugins()
+	if len(plugins) == 0 {
+		return
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: communityPluginsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	fetchVolumeMount := corev1.VolumeMount{
+		Name:      communityPluginsVolumeName,
+		MountPath: communityPluginsFetchDir,
+	}
+
+	cpuRequest := k8sresource.MustParse(initContainerCPU)
+	memoryRequest := k8sresource.MustParse(initContainerMemory)
+	containerNames := map[string]bool{}
+	downloading := false
+	for _, plugin := range plugins {
+		if plugin.Source.URL != "" {
+			if setupContainer := setupContainer(podSpec); setupContainer != nil {
+				if !downloading {
+					setupContainer.VolumeMounts = append(setupContainer.VolumeMounts, fetchVolumeMount)
+					setupContainer.Args = append(setupContainer.Args, "--community-plugins-dir="+communityPluginsFetchDir)
+					downloading = true
+				}
+				setupContainer.Args = append(setupContainer.Args, fmt.Sprintf("--community-plugin=%s,%s,%s",
+					communityPluginFileName(plugin), strings.ToLower(plugin.Source.SHA256), plugin.Source.URL))
+			}
+			continue
+		}
+
+		podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+			Name:    communityPluginContainerName(plugin.Name, containerNames),
+			Image:   plugin.Source.Image,
+			Command: []string{"sh", "-c", communityPluginCommand(plugin)},
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    cpuRequest,
+					corev1.ResourceMemory: memoryRequest,
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    cpuRequest,
+					corev1.ResourceMemory: memoryRequest,
+				},
+			},
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			VolumeMounts:             []corev1.VolumeMount{fetchVolumeMount},
+		})
+	}
+
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != "rabbitmq" {
+			continue
+		}
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      communityPluginsVolumeName,
+			MountPath: communityPluginsDir,
+			ReadOnly:  true,
+		})
+		// a plugins directory configured by the user must list communityPluginsDir itself
+		if !builder.pluginsDirConfigured() {
+			podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, corev1.EnvVar{
+				Name:  "RABBITMQ_PLUGINS_DIR",
+				Value: defaultPluginsDir + ":" + communityPluginsDir,
+			})
+		}
+	}
+}
+
+// pluginsDirConfigured reports whether the plugins directories of RabbitMQ are set in spec.rabbitmq.envConfig
+// or in the environment of the rabbitmq container of the StatefulSet override
+func (builder *StatefulSetBuilder) pluginsDirConfigured() bool {
+	if pluginsDirEnvConfig.MatchString(builder.Instance.Spec.Rabbitmq.EnvConfig) {
+		return true
+	}
+
+	override := builder.Instance.Spec.Override.StatefulSet
+	if override == nil || override.Spec == nil || override.Spec.Template == nil || override.Spec.Template.Spec == nil {
+		return false
+	}
+	for _, container := range override.Spec.Template.Spec.Containers {
+		if container.Name != "rabbitmq" {
+			continue
+		}
+		for _, env := range container.Env {
+			if env.Name == "RABBITMQ_PLUGINS_DIR" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// communityPluginContainerName returns a DNS-1123 label naming the init container of a plugin, unique among the names already used
+func communityPluginContainerName(name rabbitmqv1beta1.Plugin, used map[string]bool) string {
+	base := "fetch-" + strings.Trim(invalidContainerNameChars.ReplaceAllString(strings.ToLower(string(name)), "-"), "-")
+	// leave room for the suffix making the name unique
+	if len(base) > 59 {
+		base = base[:59]
+	}
+	base = strings.TrimSuffix(base, "-")
+
+	containerName := base
+	for i := 2; used[containerName]; i++ {
+		containerName = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[containerName] = true
+	return containerName
+}
+
+func communityPluginCommand(plugin rabbitmqv1beta1.CommunityPlugin) string {
+	pluginsPath := defaultCommunityPluginsPath
+	if plugin.Source.Path != "" {
+		pluginsPath = plugin.Source.Path
+	}
+	return fmt.Sprintf("cp %s/*.ez %s/", shellQuote(strings.TrimSuffix(pluginsPath, "/")), communityPluginsFetchDir)
+}
+
+// communityPluginFileName returns the name of the .ez file at the source URL, which RabbitMQ expects to be <plugin>-<version>.ez
+func communityPluginFileName(plugin rabbitmqv1beta1.CommunityPlugin) string {
+	if u, err := url.Parse(plugin.Source.URL); err == nil && strings.HasSuffix(u.Path, ".ez") {
+		return path.Base(u.Path)
+	}
+	return string(plugin.Name) + ".ez"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
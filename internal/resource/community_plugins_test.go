@@ -0,0 +1,217 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Community plugins", func() {
+	var (
+		instance    rabbitmqv1beta1.RabbitmqCluster
+		stsBuilder  *resource.StatefulSetBuilder
+		statefulSet *appsv1.StatefulSet
+		checksum    = strings.Repeat("ab", 32)
+	)
+
+	BeforeEach(func() {
+		instance = generateRabbitmqCluster()
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		stsBuilder = builder.StatefulSet()
+		statefulSet = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instance.Name,
+				Namespace: instance.Namespace,
+			},
+		}
+	})
+
+	It("does not change the Pod template when no plugin has a source", func() {
+		instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{{Name: "rabbitmq_in_image"}}
+		Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+		Expect(statefulSet.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+		container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+		for _, env := range container.Env {
+			Expect(env.Name).NotTo(Equal("RABBITMQ_PLUGINS_DIR"))
+		}
+	})
+
+	When("plugins are fetched from a URL and an image", func() {
+		BeforeEach(func() {
+			instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+				{
+					Name: "rabbitmq_message_timestamp",
+					Source: &rabbitmqv1beta1.CommunityPluginSource{
+						URL:    "https://example.com/releases/rabbitmq_message_timestamp-3.8.0.ez",
+						SHA256: checksum,
+					},
+				},
+				{
+					Name: "rabbitmq_from_image",
+					Source: &rabbitmqv1beta1.CommunityPluginSource{
+						Image: "registry.example.com/plugins:1.0",
+						Path:  "/opt/plugins",
+					},
+				},
+			}
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+		})
+
+		It("downloads the plugins fetched from a URL in the setup container", func() {
+			setupContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+			Expect(setupContainer.Args).To(ContainElements(
+				"--community-plugins-dir=/community-plugins",
+				"--community-plugin=rabbitmq_message_timestamp-3.8.0.ez,"+checksum+",https://example.com/releases/rabbitmq_message_timestamp-3.8.0.ez",
+			))
+			Expect(setupContainer.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      "community-plugins",
+				MountPath: "/community-plugins",
+			}))
+		})
+
+		It("adds an init container per plugin fetched from an image after the setup container", func() {
+			initContainers := statefulSet.Spec.Template.Spec.InitContainers
+			Expect(initContainers).To(HaveLen(2))
+			Expect(initContainers[0].Name).To(Equal("setup-container"))
+
+			Expect(initContainers[1].Name).To(Equal("fetch-rabbitmq-from-image"))
+			Expect(initContainers[1].Image).To(Equal("registry.example.com/plugins:1.0"))
+			Expect(initContainers[1].Command).To(Equal([]string{"sh", "-c", "cp '/opt/plugins'/*.ez /community-plugins/"}))
+			Expect(initContainers[1].VolumeMounts).To(ConsistOf(corev1.VolumeMount{
+				Name:      "community-plugins",
+				MountPath: "/community-plugins",
+			}))
+		})
+
+		It("adds the shared volume to the plugins directories of RabbitMQ", func() {
+			Expect(statefulSet.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+				Name: "community-plugins",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			}))
+
+			container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+			Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      "community-plugins",
+				MountPath: "/opt/rabbitmq/community-plugins",
+				ReadOnly:  true,
+			}))
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{
+				Name:  "RABBITMQ_PLUGINS_DIR",
+				Value: "/opt/rabbitmq/plugins:/opt/rabbitmq/community-plugins",
+			}))
+		})
+
+		It("enables the plugins", func() {
+			Expect(instance.EnabledAdditionalPlugins()).To(ContainElements(
+				rabbitmqv1beta1.Plugin("rabbitmq_message_timestamp"),
+				rabbitmqv1beta1.Plugin("rabbitmq_from_image"),
+			))
+		})
+	})
+
+	DescribeTable("invalid sources",
+		func(source rabbitmqv1beta1.CommunityPluginSource, message string) {
+			instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+				{Name: "rabbitmq_invalid", Source: &source},
+			}
+			Expect(stsBuilder.Update(statefulSet)).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("no URL or image", rabbitmqv1beta1.CommunityPluginSource{}, "one of source.url and source.image must be set"),
+		Entry("URL and image", rabbitmqv1beta1.CommunityPluginSource{URL: "https://example.com/a.ez", SHA256: checksum, Image: "image"}, "only one of source.url and source.image"),
+		Entry("URL without checksum", rabbitmqv1beta1.CommunityPluginSource{URL: "https://example.com/a.ez"}, "source.sha256 is required"),
+		Entry("relative URL", rabbitmqv1beta1.CommunityPluginSource{URL: "a.ez", SHA256: checksum}, "invalid source.url"),
+	)
+
+	It("gives the init containers unique and valid names", func() {
+		instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{}
+		for _, name := range []string{"rabbitmq_a_b", "rabbitmq_a-b", "Rabbitmq_A_B", "_" + strings.Repeat("x", 70)} {
+			instance.Spec.Rabbitmq.CommunityPlugins = append(instance.Spec.Rabbitmq.CommunityPlugins, rabbitmqv1beta1.CommunityPlugin{
+				Name:   rabbitmqv1beta1.Plugin(name),
+				Source: &rabbitmqv1beta1.CommunityPluginSource{Image: "registry.example.com/plugins:1.0"},
+			})
+		}
+		Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+		var names []string
+		for _, initContainer := range statefulSet.Spec.Template.Spec.InitContainers[1:] {
+			Expect(validation.IsDNS1123Label(initContainer.Name)).To(BeEmpty())
+			names = append(names, initContainer.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"fetch-rabbitmq-a-b",
+			"fetch-rabbitmq-a-b-2",
+			"fetch-rabbitmq-a-b-3",
+			"fetch-" + strings.Repeat("x", 53),
+		}))
+	})
+
+	When("the plugins directories are configured by the user", func() {
+		BeforeEach(func() {
+			instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{{
+				Name:   "rabbitmq_from_image",
+				Source: &rabbitmqv1beta1.CommunityPluginSource{Image: "registry.example.com/plugins:1.0"},
+			}}
+		})
+
+		It("does not set RABBITMQ_PLUGINS_DIR when it is set in envConfig", func() {
+			instance.Spec.Rabbitmq.EnvConfig = "PLUGINS_DIR=/opt/rabbitmq/plugins:/opt/rabbitmq/community-plugins:/my-plugins"
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+			container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+			for _, env := range container.Env {
+				Expect(env.Name).NotTo(Equal("RABBITMQ_PLUGINS_DIR"))
+			}
+		})
+
+		It("does not set RABBITMQ_PLUGINS_DIR when it is set in the StatefulSet override", func() {
+			instance.Spec.Override.StatefulSet = &rabbitmqv1beta1.StatefulSet{
+				Spec: &rabbitmqv1beta1.StatefulSetSpec{
+					Template: &rabbitmqv1beta1.PodTemplateSpec{
+						Spec: &corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name: "rabbitmq",
+								Env:  []corev1.EnvVar{{Name: "RABBITMQ_PLUGINS_DIR", Value: "/my-plugins"}},
+							}},
+						},
+					},
+				},
+			}
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+			container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "RABBITMQ_PLUGINS_DIR", Value: "/my-plugins"}))
+			Expect(container.Env).NotTo(ContainElement(corev1.EnvVar{
+				Name:  "RABBITMQ_PLUGINS_DIR",
+				Value: "/opt/rabbitmq/plugins:/opt/rabbitmq/community-plugins",
+			}))
+		})
+	})
+})
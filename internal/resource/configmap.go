@@ -75,6 +75,22 @@ func (builder *ServerConfigMapBuilder) Update(object runtime.Object) error {
 		}
 	}
 
+	if err := builder.setPluginConfig(defaultSection); err != nil {
+		return err
+	}
+
+	if volume, ok := additionalVolumeWithContent(builder.Instance, rabbitmqv1beta1.QuorumWALVolumeContent); ok {
+		if _, err := defaultSection.NewKey("raft.wal_data_dir", additionalVolumeMountPath(volume)); err != nil {
+			return err
+		}
+	}
+
+	if prefix := managementPathPrefix(builder.Instance); prefix != "" {
+		if _, err := defaultSection.NewKey("management.path_prefix", prefix); err != nil {
+			return err
+		}
+	}
+
 	if builder.Instance.MutualTLSEnabled() {
 		if _, err := defaultSection.NewKey("ssl_options.cacertfile", "/etc/rabbitmq-tls/"+builder.Instance.Spec.TLS.CaCertName); err != nil {
 			return err
@@ -106,6 +122,40 @@ func (builder *ServerConfigMapBuilder) Update(object runtime.Object) error {
 	return nil
 }
 
+// setPluginConfig sets the listeners of the listed plugins, and the ssl options of plugins with their own TLS listener
+func (builder *ServerConfigMapBuilder) setPluginConfig(section *ini.Section) error {
+	tlsEnabled := builder.Instance.TLSEnabled()
+	for _, plugin := range ListedPlugins(builder.Instance) {
+		for _, port := range plugin.Ports {
+			if port.ConfigKey == "" || (port.TLS && !tlsEnabled) {
+				continue
+			}
+			if _, err := section.NewKey(port.ConfigKey, fmt.Sprint(port.Port)); err != nil {
+				return err
+			}
+		}
+
+		if !tlsEnabled || plugin.TLSOptionsPrefix == "" {
+			continue
+		}
+		sslOptions := map[string]string{
+			"certfile": "/etc/rabbitmq-tls/tls.crt",
+			"keyfile":  "/etc/rabbitmq-tls/tls.key",
+		}
+		if builder.Instance.MutualTLSEnabled() {
+			sslOptions["cacertfile"] = "/etc/rabbitmq-tls/" + builder.Instance.Spec.TLS.CaCertName
+		}
+		for _, option := range []string{"certfile", "keyfile", "cacertfile"} {
+			if value, ok := sslOptions[option]; ok {
+				if _, err := section.NewKey(plugin.TLSOptionsPrefix+"."+option, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (builder *ServerConfigMapBuilder) Build() (runtime.Object, error) {
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -207,6 +207,152 @@ listeners.ssl.default                           = 5671`)))
 			})
 		})
 
+		Context("Plugins", func() {
+			It("adds a stream TLS listener when TLS is enabled", func() {
+				instance = rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "rabbit-streams",
+					},
+					Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+						TLS: rabbitmqv1beta1.TLSSpec{
+							SecretName: "tls-secret",
+						},
+						Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+							AdditionalPlugins: []rabbitmqv1beta1.Plugin{"rabbitmq_stream"},
+						},
+					},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", ContainSubstring(`
+listeners.ssl.default                           = 5671
+stream.listeners.ssl.default                    = 5551`)))
+			})
+
+			It("adds the TLS listeners and ssl options of web plugins when TLS is enabled", func() {
+				instance = rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "rabbit-web-mqtt",
+					},
+					Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+						TLS: rabbitmqv1beta1.TLSSpec{
+							SecretName:   "tls-secret",
+							CaSecretName: "tls-mutual-secret",
+							CaCertName:   "ca.certificate",
+						},
+						Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+							AdditionalPlugins: []rabbitmqv1beta1.Plugin{"rabbitmq_web_mqtt"},
+						},
+					},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", ContainSubstring(`
+web_mqtt.ssl.port                               = 15676
+web_mqtt.ssl.certfile                           = /etc/rabbitmq-tls/tls.crt
+web_mqtt.ssl.keyfile                            = /etc/rabbitmq-tls/tls.key
+web_mqtt.ssl.cacertfile                         = /etc/rabbitmq-tls/ca.certificate`)))
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", Not(ContainSubstring("mqtt.listeners"))))
+			})
+
+			It("sets the config keys of community plugin ports", func() {
+				instance = rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "rabbit-community",
+					},
+					Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+						Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+							AdditionalPlugins: []rabbitmqv1beta1.Plugin{"rabbitmq_custom"},
+							CommunityPlugins: []rabbitmqv1beta1.CommunityPlugin{
+								{
+									Name:  "rabbitmq_custom",
+									Ports: []rabbitmqv1beta1.PluginPort{{Name: "custom", Port: 4242, ConfigKey: "custom.listeners.tcp.default"}},
+								},
+							},
+						},
+					},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", MatchRegexp(`custom.listeners.tcp.default\s+= 4242`)))
+			})
+
+			It("does not add a stream TLS listener without TLS", func() {
+				instance = rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "rabbit-streams",
+					},
+					Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+						Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+							AdditionalPlugins: []rabbitmqv1beta1.Plugin{"rabbitmq_stream"},
+						},
+					},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", Not(ContainSubstring("stream.listeners.ssl"))))
+			})
+		})
+
+		Context("Additional volumes", func() {
+			It("sets raft.wal_data_dir to the volume holding the quorum queue WAL", func() {
+				instance = rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "rabbit-volumes",
+					},
+					Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+						Persistence: rabbitmqv1beta1.RabbitmqClusterPersistenceSpec{
+							AdditionalVolumes: []rabbitmqv1beta1.RabbitmqClusterAdditionalVolume{
+								{Name: "quorum-wal", Content: rabbitmqv1beta1.QuorumWALVolumeContent},
+							},
+						},
+					},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", MatchRegexp(`raft.wal_data_dir\s+= /var/lib/rabbitmq/quorum-wal\n`)))
+			})
+		})
+
+		Context("Management ingress", func() {
+			It("sets management.path_prefix when the management UI is served under a path", func() {
+				instance = rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "rabbit-management",
+					},
+					Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+						Management: &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+							Ingress: &rabbitmqv1beta1.RabbitmqClusterManagementIngressSpec{
+								Host: "rabbitmq.example.com",
+								Path: "/rabbitmq/",
+							},
+						},
+					},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", MatchRegexp(`management.path_prefix\s+= /rabbitmq\n`)))
+			})
+
+			It("does not set management.path_prefix when the management UI is served under /", func() {
+				instance = rabbitmqv1beta1.RabbitmqCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "rabbit-management",
+					},
+					Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+						Management: &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+							Ingress: &rabbitmqv1beta1.RabbitmqClusterManagementIngressSpec{
+								Host: "rabbitmq.example.com",
+							},
+						},
+					},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq.conf", Not(ContainSubstring("management.path_prefix"))))
+			})
+		})
+
 		Context("Mutual TLS", func() {
 			It("adds TLS config when TLS is enabled", func() {
 				instance = rabbitmqv1beta1.RabbitmqCluster{
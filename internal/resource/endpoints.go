@@ -0,0 +1,52 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"sort"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Endpoints returns the addresses clients connect to through the given Services, one per Service port.
+// Ports are sorted by name within each Service, and Services keep the given order.
+func Endpoints(services ...*corev1.Service) []rabbitmqv1beta1.RabbitmqClusterEndpoint {
+	var endpoints []rabbitmqv1beta1.RabbitmqClusterEndpoint
+	for _, service := range services {
+		var loadBalancerIngress []string
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.Hostname != "" {
+				loadBalancerIngress = append(loadBalancerIngress, ingress.Hostname)
+			} else if ingress.IP != "" {
+				loadBalancerIngress = append(loadBalancerIngress, ingress.IP)
+			}
+		}
+
+		ports := make([]corev1.ServicePort, len(service.Spec.Ports))
+		copy(ports, service.Spec.Ports)
+		sort.Slice(ports, func(i, j int) bool {
+			return ports[i].Name < ports[j].Name
+		})
+
+		for _, port := range ports {
+			endpoints = append(endpoints, rabbitmqv1beta1.RabbitmqClusterEndpoint{
+				Protocol:            port.Name,
+				Service:             service.Name,
+				Host:                fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
+				Port:                port.Port,
+				NodePort:            port.NodePort,
+				LoadBalancerIngress: loadBalancerIngress,
+			})
+		}
+	}
+	return endpoints
+}
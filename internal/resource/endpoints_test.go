@@ -0,0 +1,84 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Endpoints", func() {
+	It("returns an endpoint per port, sorted by port name", func() {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "rabbit-rabbitmq-client", Namespace: "rabbit-namespace"},
+			Spec: corev1.ServiceSpec{
+				Type: corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{
+					{Name: "management", Port: 15672},
+					{Name: "amqp", Port: 5672},
+				},
+			},
+		}
+
+		Expect(resource.Endpoints(service)).To(Equal([]rabbitmqv1beta1.RabbitmqClusterEndpoint{
+			{Protocol: "amqp", Service: "rabbit-rabbitmq-client", Host: "rabbit-rabbitmq-client.rabbit-namespace.svc", Port: 5672},
+			{Protocol: "management", Service: "rabbit-rabbitmq-client", Host: "rabbit-rabbitmq-client.rabbit-namespace.svc", Port: 15672},
+		}))
+	})
+
+	It("reports NodePorts and load balancer ingresses, preferring host names", func() {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "rabbit-rabbitmq-amqps", Namespace: "rabbit-namespace"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeLoadBalancer,
+				Ports: []corev1.ServicePort{{Name: "amqps", Port: 5671, NodePort: 30671}},
+			},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{
+					Ingress: []corev1.LoadBalancerIngress{
+						{IP: "203.0.113.10"},
+						{IP: "203.0.113.11", Hostname: "rabbitmq.example.com"},
+					},
+				},
+			},
+		}
+
+		Expect(resource.Endpoints(service)).To(Equal([]rabbitmqv1beta1.RabbitmqClusterEndpoint{
+			{
+				Protocol:            "amqps",
+				Service:             "rabbit-rabbitmq-amqps",
+				Host:                "rabbit-rabbitmq-amqps.rabbit-namespace.svc",
+				Port:                5671,
+				NodePort:            30671,
+				LoadBalancerIngress: []string{"203.0.113.10", "rabbitmq.example.com"},
+			},
+		}))
+	})
+
+	It("keeps the order of the Services", func() {
+		client := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "b-client", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "amqp", Port: 5672}}},
+		}
+		additional := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-additional", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "amqp", Port: 5672}}},
+		}
+
+		endpoints := resource.Endpoints(client, additional)
+		Expect(endpoints).To(HaveLen(2))
+		Expect(endpoints[0].Service).To(Equal("b-client"))
+		Expect(endpoints[1].Service).To(Equal("a-additional"))
+	})
+})
@@ -0,0 +1,99 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const HTTPRouteKind = "HTTPRoute"
+
+// GatewayGroupVersion is the API group version of the Gateway API CRDs
+var GatewayGroupVersion = schema.GroupVersion{Group: "gateway.networking.k8s.io", Version: "v1"}
+
+// ManagementHTTPRouteBuilder builds the Gateway API HTTPRoute routing the host in spec.management.ingress to the management Service.
+// Gateway API types are not vendored, so the resource is built as unstructured.
+type ManagementHTTPRouteBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+}
+
+func (builder *RabbitmqResourceBuilder) ManagementHTTPRoute() *ManagementHTTPRouteBuilder {
+	return &ManagementHTTPRouteBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+	}
+}
+
+func (builder *ManagementHTTPRouteBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *ManagementHTTPRouteBuilder) Build() (runtime.Object, error) {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(GatewayGroupVersion.WithKind(HTTPRouteKind))
+	route.SetName(builder.Instance.ChildResourceName(managementServiceName))
+	route.SetNamespace(builder.Instance.Namespace)
+	return route, nil
+}
+
+func (builder *ManagementHTTPRouteBuilder) Update(object runtime.Object) error {
+	route := object.(*unstructured.Unstructured)
+	spec := builder.Instance.Spec.Management.Ingress
+	if spec.Gateway == nil {
+		return fmt.Errorf("spec.management.ingress.gateway is required when kind is %s", HTTPRouteKind)
+	}
+
+	route.SetLabels(metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels))
+	route.SetAnnotations(metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(route.GetAnnotations(), builder.Instance.Annotations), spec.Annotations))
+
+	parentRef := map[string]interface{}{
+		"name": spec.Gateway.Name,
+	}
+	if spec.Gateway.Namespace != "" {
+		parentRef["namespace"] = spec.Gateway.Namespace
+	}
+
+	route.Object["spec"] = map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"hostnames":  []interface{}{spec.Host},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": managementPath(builder.Instance),
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": builder.Instance.ChildResourceName(managementServiceName),
+						"port": int64(managementPort),
+					},
+				},
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, route, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
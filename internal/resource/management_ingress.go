@@ -0,0 +1,111 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ManagementIngressBuilder builds the Ingress routing the host in spec.management.ingress to the management Service.
+type ManagementIngressBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+}
+
+func (builder *RabbitmqResourceBuilder) ManagementIngress() *ManagementIngressBuilder {
+	return &ManagementIngressBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+	}
+}
+
+func (builder *ManagementIngressBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *ManagementIngressBuilder) Build() (runtime.Object, error) {
+	return &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(managementServiceName),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *ManagementIngressBuilder) Update(object runtime.Object) error {
+	ingress := object.(*networkingv1beta1.Ingress)
+	spec := builder.Instance.Spec.Management.Ingress
+
+	ingress.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	ingress.Annotations = metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(ingress.Annotations, builder.Instance.Annotations), spec.Annotations)
+
+	pathType := networkingv1beta1.PathTypePrefix
+	ingress.Spec.IngressClassName = spec.IngressClassName
+	ingress.Spec.Rules = []networkingv1beta1.IngressRule{
+		{
+			Host: spec.Host,
+			IngressRuleValue: networkingv1beta1.IngressRuleValue{
+				HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+					Paths: []networkingv1beta1.HTTPIngressPath{
+						{
+							Path:     managementPath(builder.Instance),
+							PathType: &pathType,
+							Backend: networkingv1beta1.IngressBackend{
+								ServiceName: builder.Instance.ChildResourceName(managementServiceName),
+								ServicePort: intstr.FromString(managementPortName),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ingress.Spec.TLS = nil
+	if spec.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{
+			{
+				Hosts:      []string{spec.Host},
+				SecretName: spec.TLSSecretName,
+			},
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, ingress, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
+
+// managementPath returns the path the management UI is served under
+func managementPath(instance *rabbitmqv1beta1.RabbitmqCluster) string {
+	if instance.Spec.Management.Ingress.Path == "" {
+		return "/"
+	}
+	return instance.Spec.Management.Ingress.Path
+}
+
+// managementPathPrefix returns the management.path_prefix to configure, or an empty string if the UI is served under /
+func managementPathPrefix(instance *rabbitmqv1beta1.RabbitmqCluster) string {
+	if !instance.ManagementIngressEnabled() {
+		return ""
+	}
+	return strings.TrimRight(managementPath(instance), "/")
+}
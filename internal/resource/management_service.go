@@ -0,0 +1,97 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	managementServiceName = "management"
+	managementPortName    = "management"
+	managementPort        = 15672
+	// name of a port serving the management API over TLS, e.g. added with spec.override.clientService
+	managementTLSPortName = "management-tls"
+)
+
+// ManagementServiceBuilder builds the ClusterIP Service exposing only the management port.
+// It is the backend of the management Ingress or HTTPRoute.
+type ManagementServiceBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+}
+
+func (builder *RabbitmqResourceBuilder) ManagementService() *ManagementServiceBuilder {
+	return &ManagementServiceBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+	}
+}
+
+func (builder *ManagementServiceBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *ManagementServiceBuilder) Build() (runtime.Object, error) {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(managementServiceName),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *ManagementServiceBuilder) Update(object runtime.Object) error {
+	service := object.(*corev1.Service)
+	service.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	service.Annotations = metadata.ReconcileAndFilterAnnotations(service.Annotations, builder.Instance.Annotations)
+
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	service.Spec.Selector = metadata.LabelSelector(builder.Instance.Name)
+	service.Spec.Ports = []corev1.ServicePort{
+		{
+			Protocol: corev1.ProtocolTCP,
+			Port:     managementPort,
+			Name:     managementPortName,
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, service, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
+
+// ManagementURL returns the base URL of the management API behind the Service, with the scheme and port of its management port.
+// The plain HTTP port is preferred when the Service exposes both.
+func ManagementURL(instance *rabbitmqv1beta1.RabbitmqCluster, service *corev1.Service) (string, error) {
+	var scheme string
+	var port int32
+	for _, servicePort := range service.Spec.Ports {
+		switch {
+		case servicePort.Name == managementPortName:
+			scheme, port = "http", servicePort.Port
+		case servicePort.Name == managementTLSPortName && scheme == "":
+			scheme, port = "https", servicePort.Port
+		}
+	}
+	if scheme == "" {
+		return "", fmt.Errorf("service %s has no %s or %s port", service.Name, managementPortName, managementTLSPortName)
+	}
+	return fmt.Sprintf("%s://%s.%s.svc:%d%s", scheme, service.Name, service.Namespace, port, managementPathPrefix(instance)), nil
+}
@@ -0,0 +1,218 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Management exposure", func() {
+	var (
+		instance rabbitmqv1beta1.RabbitmqCluster
+		builder  *resource.RabbitmqResourceBuilder
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbit",
+				Namespace: "rabbit-namespace",
+			},
+		}
+		instance.Spec.Management = &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+			Ingress: &rabbitmqv1beta1.RabbitmqClusterManagementIngressSpec{
+				Host: "rabbitmq.example.com",
+			},
+		}
+		builder = &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+	})
+
+	Context("ManagementService", func() {
+		It("exposes only the management port through a ClusterIP Service", func() {
+			serviceBuilder := builder.ManagementService()
+			obj, err := serviceBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			service := obj.(*corev1.Service)
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Name).To(Equal("rabbit-rabbitmq-management"))
+			Expect(service.Namespace).To(Equal("rabbit-namespace"))
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(service.Spec.Selector).To(Equal(map[string]string{"app.kubernetes.io/name": "rabbit"}))
+			Expect(service.Spec.Ports).To(ConsistOf(corev1.ServicePort{
+				Name:     "management",
+				Protocol: corev1.ProtocolTCP,
+				Port:     15672,
+			}))
+			Expect(service.OwnerReferences).To(HaveLen(1))
+		})
+	})
+
+	Context("ManagementURL", func() {
+		var service *corev1.Service
+
+		BeforeEach(func() {
+			instance.Spec.Management = nil
+			service = &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "rabbit-rabbitmq-client", Namespace: "rabbit-namespace"},
+			}
+		})
+
+		It("uses the port of the management port of the Service", func() {
+			service.Spec.Ports = []corev1.ServicePort{
+				{Name: "amqp", Port: 5672},
+				{Name: "management", Port: 8080},
+			}
+			Expect(resource.ManagementURL(&instance, service)).To(Equal("http://rabbit-rabbitmq-client.rabbit-namespace.svc:8080"))
+		})
+
+		It("uses HTTPS when the Service only exposes the management API over TLS", func() {
+			service.Spec.Ports = []corev1.ServicePort{{Name: "management-tls", Port: 15671}}
+			Expect(resource.ManagementURL(&instance, service)).To(Equal("https://rabbit-rabbitmq-client.rabbit-namespace.svc:15671"))
+
+			service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{Name: "management", Port: 15672})
+			Expect(resource.ManagementURL(&instance, service)).To(Equal("http://rabbit-rabbitmq-client.rabbit-namespace.svc:15672"))
+		})
+
+		It("appends the path prefix of the management Ingress", func() {
+			instance.Spec.Management = &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+				Ingress: &rabbitmqv1beta1.RabbitmqClusterManagementIngressSpec{
+					Host: "rabbitmq.example.com",
+					Path: "/rabbitmq/",
+				},
+			}
+			service.Spec.Ports = []corev1.ServicePort{{Name: "management", Port: 15672}}
+			Expect(resource.ManagementURL(&instance, service)).To(Equal("http://rabbit-rabbitmq-client.rabbit-namespace.svc:15672/rabbitmq"))
+		})
+
+		It("errors when the Service has no management port", func() {
+			service.Spec.Ports = []corev1.ServicePort{{Name: "amqp", Port: 5672}}
+			_, err := resource.ManagementURL(&instance, service)
+			Expect(err).To(MatchError("service rabbit-rabbitmq-client has no management or management-tls port"))
+		})
+	})
+
+	Context("ManagementIngress", func() {
+		var (
+			ingressBuilder *resource.ManagementIngressBuilder
+			ingress        *networkingv1beta1.Ingress
+		)
+
+		BeforeEach(func() {
+			ingressBuilder = builder.ManagementIngress()
+			obj, err := ingressBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			ingress = obj.(*networkingv1beta1.Ingress)
+		})
+
+		It("routes the host to the management Service", func() {
+			Expect(ingressBuilder.Update(ingress)).To(Succeed())
+
+			Expect(ingress.Name).To(Equal("rabbit-rabbitmq-management"))
+			Expect(ingress.Spec.Rules).To(HaveLen(1))
+			rule := ingress.Spec.Rules[0]
+			Expect(rule.Host).To(Equal("rabbitmq.example.com"))
+			Expect(rule.HTTP.Paths).To(HaveLen(1))
+			Expect(rule.HTTP.Paths[0].Path).To(Equal("/"))
+			Expect(*rule.HTTP.Paths[0].PathType).To(Equal(networkingv1beta1.PathTypePrefix))
+			Expect(rule.HTTP.Paths[0].Backend).To(Equal(networkingv1beta1.IngressBackend{
+				ServiceName: "rabbit-rabbitmq-management",
+				ServicePort: intstr.FromString("management"),
+			}))
+			Expect(ingress.Spec.TLS).To(BeEmpty())
+			Expect(ingress.OwnerReferences).To(HaveLen(1))
+		})
+
+		It("uses the path, TLS secret, class and annotations from spec.management.ingress", func() {
+			className := "nginx"
+			instance.Spec.Management.Ingress.Path = "/rabbitmq"
+			instance.Spec.Management.Ingress.TLSSecretName = "rabbitmq-tls"
+			instance.Spec.Management.Ingress.IngressClassName = &className
+			instance.Spec.Management.Ingress.Annotations = map[string]string{"ingress-annotation": "some-value"}
+			Expect(ingressBuilder.Update(ingress)).To(Succeed())
+
+			Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Path).To(Equal("/rabbitmq"))
+			Expect(ingress.Spec.TLS).To(ConsistOf(networkingv1beta1.IngressTLS{
+				Hosts:      []string{"rabbitmq.example.com"},
+				SecretName: "rabbitmq-tls",
+			}))
+			Expect(ingress.Spec.IngressClassName).To(Equal(&className))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("ingress-annotation", "some-value"))
+		})
+	})
+
+	Context("ManagementHTTPRoute", func() {
+		var (
+			routeBuilder *resource.ManagementHTTPRouteBuilder
+			route        *unstructured.Unstructured
+		)
+
+		BeforeEach(func() {
+			instance.Spec.Management.Ingress.Kind = "HTTPRoute"
+			routeBuilder = builder.ManagementHTTPRoute()
+			obj, err := routeBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			route = obj.(*unstructured.Unstructured)
+		})
+
+		It("builds a Gateway API HTTPRoute", func() {
+			Expect(route.GetAPIVersion()).To(Equal("gateway.networking.k8s.io/v1"))
+			Expect(route.GetKind()).To(Equal("HTTPRoute"))
+			Expect(route.GetName()).To(Equal("rabbit-rabbitmq-management"))
+			Expect(route.GetNamespace()).To(Equal("rabbit-namespace"))
+		})
+
+		It("attaches to the Gateway and routes the host to the management Service", func() {
+			instance.Spec.Management.Ingress.Path = "/rabbitmq"
+			instance.Spec.Management.Ingress.Gateway = &rabbitmqv1beta1.RabbitmqClusterGatewayReference{
+				Name:      "public",
+				Namespace: "gateways",
+			}
+			Expect(routeBuilder.Update(route)).To(Succeed())
+
+			parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+			Expect(parentRefs).To(ConsistOf(map[string]interface{}{"name": "public", "namespace": "gateways"}))
+			hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			Expect(hostnames).To(ConsistOf("rabbitmq.example.com"))
+
+			rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+			Expect(rules).To(HaveLen(1))
+			rule := rules[0].(map[string]interface{})
+			Expect(rule["matches"]).To(ConsistOf(map[string]interface{}{
+				"path": map[string]interface{}{"type": "PathPrefix", "value": "/rabbitmq"},
+			}))
+			Expect(rule["backendRefs"]).To(ConsistOf(map[string]interface{}{
+				"name": "rabbit-rabbitmq-management",
+				"port": int64(15672),
+			}))
+			Expect(route.GetOwnerReferences()).To(HaveLen(1))
+		})
+
+		It("requires a Gateway", func() {
+			Expect(routeBuilder.Update(route)).To(MatchError(ContainSubstring("spec.management.ingress.gateway is required")))
+		})
+	})
+})
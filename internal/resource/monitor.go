@@ -0,0 +1,113 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	monitorName        = "monitor"
+	prometheusPortName = "prometheus"
+	PodMonitorKind     = "PodMonitor"
+	ServiceMonitorKind = "ServiceMonitor"
+)
+
+// MonitoringGroupVersion is the API group version of the Prometheus Operator CRDs
+var MonitoringGroupVersion = schema.GroupVersion{Group: "monitoring.coreos.com", Version: "v1"}
+
+// MonitorBuilder builds a PodMonitor or ServiceMonitor scraping the rabbitmq_prometheus endpoint.
+// Prometheus Operator types are not vendored, so the resource is built as unstructured.
+type MonitorBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+}
+
+func (builder *RabbitmqResourceBuilder) Monitor() *MonitorBuilder {
+	return &MonitorBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+	}
+}
+
+func (builder *MonitorBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *MonitorBuilder) Build() (runtime.Object, error) {
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(MonitoringGroupVersion.WithKind(builder.kind()))
+	monitor.SetName(builder.Instance.ChildResourceName(monitorName))
+	monitor.SetNamespace(builder.Instance.Namespace)
+	return monitor, nil
+}
+
+func (builder *MonitorBuilder) Update(object runtime.Object) error {
+	monitor := object.(*unstructured.Unstructured)
+	monitor.SetLabels(monitoringLabels(builder.Instance))
+
+	endpoint := map[string]interface{}{
+		"port": prometheusPortName,
+	}
+	if builder.Instance.Spec.Monitoring.Interval != "" {
+		endpoint["interval"] = builder.Instance.Spec.Monitoring.Interval
+	}
+
+	endpointsField := "podMetricsEndpoints"
+	if builder.kind() == ServiceMonitorKind {
+		endpointsField = "endpoints"
+	}
+
+	monitor.Object["spec"] = map[string]interface{}{
+		endpointsField: []interface{}{endpoint},
+		"selector": map[string]interface{}{
+			"matchLabels": toInterfaceMap(metadata.LabelSelector(builder.Instance.Name)),
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{builder.Instance.Namespace},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, monitor, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
+
+func (builder *MonitorBuilder) kind() string {
+	if builder.Instance.ServiceMonitorEnabled() {
+		return ServiceMonitorKind
+	}
+	return PodMonitorKind
+}
+
+func monitoringLabels(instance *rabbitmqv1beta1.RabbitmqCluster) map[string]string {
+	labels := metadata.GetLabels(instance.Name, instance.Labels)
+	for k, v := range instance.Spec.Monitoring.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
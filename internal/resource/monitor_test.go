@@ -0,0 +1,127 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Monitor", func() {
+	var (
+		instance       rabbitmqv1beta1.RabbitmqCluster
+		scheme         *runtime.Scheme
+		monitorBuilder *resource.MonitorBuilder
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbit",
+				Namespace: "rabbit-namespace",
+			},
+		}
+		instance.Spec.Monitoring = &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{}
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		monitorBuilder = builder.Monitor()
+	})
+
+	Context("Build", func() {
+		It("builds a PodMonitor by default", func() {
+			obj, err := monitorBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			monitor := obj.(*unstructured.Unstructured)
+
+			Expect(monitor.GetAPIVersion()).To(Equal("monitoring.coreos.com/v1"))
+			Expect(monitor.GetKind()).To(Equal("PodMonitor"))
+			Expect(monitor.GetName()).To(Equal("rabbit-rabbitmq-monitor"))
+			Expect(monitor.GetNamespace()).To(Equal("rabbit-namespace"))
+		})
+
+		It("builds a ServiceMonitor when requested", func() {
+			instance.Spec.Monitoring.MonitorKind = "ServiceMonitor"
+			obj, err := monitorBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(obj.(*unstructured.Unstructured).GetKind()).To(Equal("ServiceMonitor"))
+		})
+	})
+
+	Context("Update", func() {
+		var monitor *unstructured.Unstructured
+
+		BeforeEach(func() {
+			instance.Labels = map[string]string{"foo": "bar"}
+			instance.Spec.Monitoring.Labels = map[string]string{"release": "prometheus"}
+			instance.Spec.Monitoring.Interval = "15s"
+			obj, err := monitorBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			monitor = obj.(*unstructured.Unstructured)
+		})
+
+		It("sets the labels of the CR and spec.monitoring", func() {
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+
+			Expect(monitor.GetLabels()).To(SatisfyAll(
+				HaveKeyWithValue("app.kubernetes.io/name", "rabbit"),
+				HaveKeyWithValue("foo", "bar"),
+				HaveKeyWithValue("release", "prometheus"),
+			))
+		})
+
+		It("scrapes the prometheus port of the RabbitMQ Pods", func() {
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+
+			endpoints, found, err := unstructured.NestedSlice(monitor.Object, "spec", "podMetricsEndpoints")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(endpoints).To(ConsistOf(map[string]interface{}{
+				"port":     "prometheus",
+				"interval": "15s",
+			}))
+
+			selector, _, err := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector).To(Equal(map[string]string{"app.kubernetes.io/name": "rabbit"}))
+
+			namespaces, _, err := unstructured.NestedStringSlice(monitor.Object, "spec", "namespaceSelector", "matchNames")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaces).To(ConsistOf("rabbit-namespace"))
+		})
+
+		It("uses endpoints for a ServiceMonitor", func() {
+			instance.Spec.Monitoring.MonitorKind = "ServiceMonitor"
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+
+			_, found, err := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+		})
+
+		It("sets the owner reference", func() {
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+
+			Expect(monitor.GetOwnerReferences()).To(HaveLen(1))
+			Expect(monitor.GetOwnerReferences()[0].Name).To(Equal("rabbit"))
+		})
+	})
+})
@@ -0,0 +1,148 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"sort"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	networkPolicyName = "server"
+	epmdPort          = 4369
+	distributionPort  = 25672
+	prometheusPort    = 15692
+	// range of the ports stream replicas replicate on, the osiris port_range
+	streamReplicationPortMin = 6000
+	streamReplicationPortMax = 6500
+)
+
+// NetworkPolicyBuilder builds the NetworkPolicy isolating the RabbitMQ Pods.
+// Inter-node ports are restricted to the Pods of the RabbitmqCluster, and client ports follow the ports of the client Service.
+type NetworkPolicyBuilder struct {
+	Instance          *rabbitmqv1beta1.RabbitmqCluster
+	Scheme            *runtime.Scheme
+	OperatorPodLabels map[string]string
+}
+
+func (builder *RabbitmqResourceBuilder) NetworkPolicy() *NetworkPolicyBuilder {
+	return &NetworkPolicyBuilder{
+		Instance:          builder.Instance,
+		Scheme:            builder.Scheme,
+		OperatorPodLabels: builder.OperatorPodLabels,
+	}
+}
+
+func (builder *NetworkPolicyBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *NetworkPolicyBuilder) Build() (runtime.Object, error) {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(networkPolicyName),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *NetworkPolicyBuilder) Update(object runtime.Object) error {
+	networkPolicy := object.(*networkingv1.NetworkPolicy)
+	networkPolicy.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	networkPolicy.Annotations = metadata.ReconcileAndFilterAnnotations(networkPolicy.GetAnnotations(), builder.Instance.Annotations)
+
+	spec := builder.Instance.Spec.NetworkPolicy
+	rabbitmqPods := metav1.LabelSelector{MatchLabels: metadata.LabelSelector(builder.Instance.Name)}
+
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &rabbitmqPods}},
+			Ports: networkPolicyPorts(builder.interNodePorts()...),
+		},
+		{
+			From:  spec.ClientPeers,
+			Ports: networkPolicyPorts(builder.clientPorts()...),
+		},
+		{
+			From:  spec.PrometheusPeers,
+			Ports: networkPolicyPorts(prometheusPort),
+		},
+	}
+	// the operator queries the management API for the status of the RabbitmqCluster
+	// its Pods are selected by their labels in any namespace, as Namespaces are only labelled with their name from Kubernetes 1.21
+	if len(builder.OperatorPodLabels) > 0 && len(spec.ClientPeers) > 0 {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{},
+				PodSelector:       &metav1.LabelSelector{MatchLabels: builder.OperatorPodLabels},
+			}},
+			Ports: networkPolicyPorts(managementPort),
+		})
+	}
+
+	networkPolicy.Spec = networkingv1.NetworkPolicySpec{
+		PodSelector: rabbitmqPods,
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		Ingress:     ingress,
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, networkPolicy, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
+
+// interNodePorts returns the ports the RabbitMQ nodes reach each other on, including stream replication when the stream plugin is enabled
+func (builder *NetworkPolicyBuilder) interNodePorts() []int32 {
+	ports := []int32{epmdPort, distributionPort}
+	if builder.Instance.StreamEnabled() {
+		// the NetworkPolicy API of the supported Kubernetes versions has no port ranges
+		for port := int32(streamReplicationPortMin); port <= streamReplicationPortMax; port++ {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// clientPorts returns the ports of the client Service, which follow the enabled plugins and TLS, except the Prometheus port
+func (builder *NetworkPolicyBuilder) clientPorts() []int32 {
+	clientServiceBuilder := &ClientServiceBuilder{Instance: builder.Instance}
+	var ports []int32
+	for _, port := range clientServiceBuilder.updatePorts(nil) {
+		if port.Port != prometheusPort {
+			ports = append(ports, port.Port)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	return ports
+}
+
+func networkPolicyPorts(ports ...int32) []networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	policyPorts := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		policyPort := intstr.FromInt(int(port))
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &policyPort,
+		})
+	}
+	return policyPorts
+}
@@ -0,0 +1,149 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("NetworkPolicy", func() {
+	var (
+		instance      rabbitmqv1beta1.RabbitmqCluster
+		policyBuilder *resource.NetworkPolicyBuilder
+		networkPolicy *networkingv1.NetworkPolicy
+	)
+
+	ports := func(rule networkingv1.NetworkPolicyIngressRule) []int {
+		var ports []int
+		for _, port := range rule.Ports {
+			Expect(*port.Protocol).To(Equal(corev1.ProtocolTCP))
+			ports = append(ports, port.Port.IntValue())
+		}
+		return ports
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbit",
+				Namespace: "rabbit-namespace",
+			},
+		}
+		instance.Spec.NetworkPolicy = &rabbitmqv1beta1.RabbitmqClusterNetworkPolicySpec{}
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance:          &instance,
+			Scheme:            scheme,
+			OperatorPodLabels: map[string]string{"app.kubernetes.io/name": "rabbitmq-cluster-operator"},
+		}
+		policyBuilder = builder.NetworkPolicy()
+		obj, err := policyBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		networkPolicy = obj.(*networkingv1.NetworkPolicy)
+	})
+
+	Context("Build", func() {
+		It("sets the name and namespace", func() {
+			Expect(networkPolicy.Name).To(Equal("rabbit-rabbitmq-server"))
+			Expect(networkPolicy.Namespace).To(Equal("rabbit-namespace"))
+		})
+	})
+
+	Context("Update", func() {
+		It("isolates ingress traffic to the RabbitMQ Pods", func() {
+			Expect(policyBuilder.Update(networkPolicy)).To(Succeed())
+
+			Expect(networkPolicy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "rabbit"}))
+			Expect(networkPolicy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+			Expect(networkPolicy.OwnerReferences).To(HaveLen(1))
+		})
+
+		It("only allows epmd and Erlang distribution traffic between the RabbitMQ Pods", func() {
+			Expect(policyBuilder.Update(networkPolicy)).To(Succeed())
+
+			rule := networkPolicy.Spec.Ingress[0]
+			Expect(ports(rule)).To(Equal([]int{4369, 25672}))
+			Expect(rule.From).To(HaveLen(1))
+			Expect(rule.From[0].PodSelector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "rabbit"}))
+		})
+
+		It("allows the client ports and Prometheus scraping from any source by default", func() {
+			Expect(policyBuilder.Update(networkPolicy)).To(Succeed())
+
+			Expect(networkPolicy.Spec.Ingress).To(HaveLen(3))
+			Expect(ports(networkPolicy.Spec.Ingress[1])).To(Equal([]int{5672, 15672}))
+			Expect(networkPolicy.Spec.Ingress[1].From).To(BeEmpty())
+			Expect(ports(networkPolicy.Spec.Ingress[2])).To(Equal([]int{15692}))
+			Expect(networkPolicy.Spec.Ingress[2].From).To(BeEmpty())
+		})
+
+		It("follows the ports of enabled plugins and TLS", func() {
+			instance.Spec.TLS.SecretName = "tls-secret"
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_mqtt", "rabbitmq_stream"}
+			Expect(policyBuilder.Update(networkPolicy)).To(Succeed())
+
+			Expect(ports(networkPolicy.Spec.Ingress[1])).To(Equal([]int{1883, 5551, 5552, 5671, 5672, 8883, 15672}))
+		})
+
+		It("allows stream replication between the RabbitMQ Pods when the stream plugin is enabled", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_stream"}
+			Expect(policyBuilder.Update(networkPolicy)).To(Succeed())
+
+			interNodePorts := ports(networkPolicy.Spec.Ingress[0])
+			Expect(interNodePorts).To(HaveLen(2 + 501))
+			Expect(interNodePorts[:3]).To(Equal([]int{4369, 25672, 6000}))
+			Expect(interNodePorts[len(interNodePorts)-1]).To(Equal(6500))
+		})
+
+		It("restricts client ports and Prometheus scraping to the configured peers", func() {
+			clientPeers := []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "messaging"}},
+			}}
+			prometheusPeers := []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "monitoring"}},
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "prometheus"}},
+			}}
+			instance.Spec.NetworkPolicy.ClientPeers = clientPeers
+			instance.Spec.NetworkPolicy.PrometheusPeers = prometheusPeers
+			Expect(policyBuilder.Update(networkPolicy)).To(Succeed())
+
+			Expect(networkPolicy.Spec.Ingress[1].From).To(Equal(clientPeers))
+			Expect(networkPolicy.Spec.Ingress[2].From).To(Equal(prometheusPeers))
+
+			By("allowing the operator to reach the management API")
+			Expect(networkPolicy.Spec.Ingress).To(HaveLen(4))
+			Expect(ports(networkPolicy.Spec.Ingress[3])).To(Equal([]int{15672}))
+			Expect(networkPolicy.Spec.Ingress[3].From).To(Equal([]networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{},
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "rabbitmq-cluster-operator"}},
+			}}))
+		})
+
+		It("does not allow the operator when its Pod labels are unknown", func() {
+			policyBuilder.OperatorPodLabels = nil
+			instance.Spec.NetworkPolicy.ClientPeers = []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "messaging"}},
+			}}
+			Expect(policyBuilder.Update(networkPolicy)).To(Succeed())
+
+			Expect(networkPolicy.Spec.Ingress).To(HaveLen(3))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// PerPodServiceBuilder builds the Service exposing the RabbitMQ Pod with the given StatefulSet ordinal.
+type PerPodServiceBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+	Ordinal  int32
+}
+
+func (builder *RabbitmqResourceBuilder) PerPodService(ordinal int32) *PerPodServiceBuilder {
+	return &PerPodServiceBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+		Ordinal:  ordinal,
+	}
+}
+
+// PerPodServiceName returns the name of the Service of the RabbitMQ Pod with the given ordinal; it matches the Pod name.
+func PerPodServiceName(instance *rabbitmqv1beta1.RabbitmqCluster, ordinal int32) string {
+	return fmt.Sprintf("%s-%d", instance.ChildResourceName("server"), ordinal)
+}
+
+// IsPerPodService reports whether the Service selects a single Pod of a StatefulSet.
+func IsPerPodService(service *corev1.Service) bool {
+	_, ok := service.Spec.Selector[appsv1.StatefulSetPodNameLabel]
+	return ok
+}
+
+func (builder *PerPodServiceBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *PerPodServiceBuilder) Build() (runtime.Object, error) {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PerPodServiceName(builder.Instance, builder.Ordinal),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *PerPodServiceBuilder) Update(object runtime.Object) error {
+	service := object.(*corev1.Service)
+	spec := builder.Instance.Spec.PerPodService
+
+	service.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	service.Annotations = metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(service.Annotations, builder.Instance.Annotations), spec.Annotations)
+
+	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	if spec.Type != "" {
+		service.Spec.Type = spec.Type
+	}
+	service.Spec.Selector = map[string]string{
+		appsv1.StatefulSetPodNameLabel: PerPodServiceName(builder.Instance, builder.Ordinal),
+	}
+	// the per-pod Services expose the same ports as the client Service, except the Prometheus port
+	clientServiceBuilder := &ClientServiceBuilder{Instance: builder.Instance}
+	service.Spec.Ports = clientServiceBuilder.updatePortsWithoutPrometheus(service.Spec.Ports)
+
+	if err := controllerutil.SetControllerReference(builder.Instance, service, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("PerPodService", func() {
+	var (
+		instance       rabbitmqv1beta1.RabbitmqCluster
+		serviceBuilder *resource.PerPodServiceBuilder
+		service        *corev1.Service
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbit",
+				Namespace: "rabbit-namespace",
+			},
+		}
+		instance.Spec.PerPodService = &rabbitmqv1beta1.RabbitmqClusterPerPodServiceSpec{}
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		serviceBuilder = builder.PerPodService(2)
+		obj, err := serviceBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		service = obj.(*corev1.Service)
+	})
+
+	Context("Build", func() {
+		It("names the Service after the Pod", func() {
+			Expect(service.Name).To(Equal("rabbit-rabbitmq-server-2"))
+			Expect(service.Namespace).To(Equal("rabbit-namespace"))
+		})
+	})
+
+	Context("Update", func() {
+		It("selects the single Pod with the ordinal of the builder", func() {
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Spec.Selector).To(Equal(map[string]string{
+				"statefulset.kubernetes.io/pod-name": "rabbit-rabbitmq-server-2",
+			}))
+			Expect(resource.IsPerPodService(service)).To(BeTrue())
+		})
+
+		It("defaults to a LoadBalancer Service", func() {
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		})
+
+		It("uses the type and annotations from spec.perPodService", func() {
+			instance.Spec.PerPodService.Type = corev1.ServiceTypeNodePort
+			instance.Spec.PerPodService.Annotations = map[string]string{"service-annotation": "some-value"}
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+			Expect(service.Annotations).To(HaveKeyWithValue("service-annotation", "some-value"))
+		})
+
+		It("exposes the client ports and preserves allocated node ports", func() {
+			service.Spec.Ports = []corev1.ServicePort{
+				{Name: "amqp", Port: 5672, Protocol: corev1.ProtocolTCP, NodePort: 30001},
+			}
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Spec.Ports).To(ConsistOf(
+				corev1.ServicePort{Name: "amqp", Port: 5672, Protocol: corev1.ProtocolTCP, NodePort: 30001},
+				corev1.ServicePort{Name: "management", Port: 15672, Protocol: corev1.ProtocolTCP},
+			))
+		})
+
+		It("does not expose the Prometheus port, which the ServiceMonitor scrapes through the client Service", func() {
+			instance.Spec.Monitoring = &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{MonitorKind: "ServiceMonitor"}
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			for _, port := range service.Spec.Ports {
+				Expect(port.Name).NotTo(Equal("prometheus"))
+			}
+		})
+
+		It("sets the labels and owner reference", func() {
+			Expect(serviceBuilder.Update(service)).To(Succeed())
+
+			Expect(service.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "rabbit"))
+			Expect(service.OwnerReferences).To(HaveLen(1))
+			Expect(service.OwnerReferences[0].Name).To(Equal("rabbit"))
+		})
+	})
+})
@@ -0,0 +1,171 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+)
+
+// PluginDefinition describes the ports and dependencies of a RabbitMQ plugin.
+type PluginDefinition struct {
+	Name  rabbitmqv1beta1.Plugin
+	Ports []rabbitmqv1beta1.PluginPort
+	// Plugins enabled by RabbitMQ along with this plugin
+	Dependencies []rabbitmqv1beta1.Plugin
+	// Prefix of the rabbitmq.conf ssl options of a plugin with its own TLS listener, e.g. web_mqtt.ssl
+	TLSOptionsPrefix string
+}
+
+// pluginCatalog lists the plugins shipped with RabbitMQ that need ports or configuration.
+// The order determines the order of the container ports, so new plugins must be appended.
+var pluginCatalog = []PluginDefinition{
+	{
+		Name: "rabbitmq_mqtt",
+		Ports: []rabbitmqv1beta1.PluginPort{
+			{Name: "mqtt", Port: 1883},
+			{Name: "mqtts", Port: 8883, TLS: true, ConfigKey: "mqtt.listeners.ssl.default"},
+		},
+	},
+	{
+		Name:         "rabbitmq_web_mqtt",
+		Dependencies: []rabbitmqv1beta1.Plugin{"rabbitmq_mqtt", "rabbitmq_web_dispatch"},
+		Ports: []rabbitmqv1beta1.PluginPort{
+			{Name: "web-mqtt", Port: 15675},
+			{Name: "web-mqtt-tls", Port: 15676, TLS: true, ConfigKey: "web_mqtt.ssl.port"},
+		},
+		TLSOptionsPrefix: "web_mqtt.ssl",
+	},
+	{
+		Name: "rabbitmq_stomp",
+		Ports: []rabbitmqv1beta1.PluginPort{
+			{Name: "stomp", Port: 61613},
+			{Name: "stomps", Port: 61614, TLS: true, ConfigKey: "stomp.listeners.ssl.1"},
+		},
+	},
+	{
+		Name:         "rabbitmq_web_stomp",
+		Dependencies: []rabbitmqv1beta1.Plugin{"rabbitmq_stomp", "rabbitmq_web_dispatch"},
+		Ports: []rabbitmqv1beta1.PluginPort{
+			{Name: "web-stomp", Port: 15674},
+			{Name: "web-stomp-tls", Port: 15673, TLS: true, ConfigKey: "web_stomp.ssl.port"},
+		},
+		TLSOptionsPrefix: "web_stomp.ssl",
+	},
+	{
+		Name: "rabbitmq_stream",
+		Ports: []rabbitmqv1beta1.PluginPort{
+			{Name: "stream", Port: 5552},
+			{Name: "streams", Port: 5551, TLS: true, ConfigKey: "stream.listeners.ssl.default"},
+		},
+	},
+	{
+		// AMQP 1.0 is served on the AMQP 0-9-1 listeners
+		Name: "rabbitmq_amqp1_0",
+	},
+	{
+		// serves the HTTP API of the management plugin and the web plugins on their own ports
+		Name: "rabbitmq_web_dispatch",
+	},
+	{
+		Name:         "rabbitmq_web_mqtt_examples",
+		Dependencies: []rabbitmqv1beta1.Plugin{"rabbitmq_web_mqtt"},
+		Ports: []rabbitmqv1beta1.PluginPort{
+			{Name: "web-examples", Port: 15670},
+		},
+	},
+	{
+		Name:         "rabbitmq_web_stomp_examples",
+		Dependencies: []rabbitmqv1beta1.Plugin{"rabbitmq_web_stomp"},
+		Ports: []rabbitmqv1beta1.PluginPort{
+			{Name: "web-examples", Port: 15670},
+		},
+	},
+}
+
+// PluginCatalog returns the built-in plugin catalog extended with the community plugins of the RabbitmqCluster.
+// A community plugin with the name of a built-in plugin replaces it.
+func PluginCatalog(instance *rabbitmqv1beta1.RabbitmqCluster) []PluginDefinition {
+	catalog := make([]PluginDefinition, 0, len(pluginCatalog)+len(instance.Spec.Rabbitmq.CommunityPlugins))
+	community := map[rabbitmqv1beta1.Plugin]bool{}
+	for _, plugin := range instance.Spec.Rabbitmq.CommunityPlugins {
+		community[plugin.Name] = true
+	}
+	for _, plugin := range pluginCatalog {
+		if !community[plugin.Name] {
+			catalog = append(catalog, plugin)
+		}
+	}
+	for _, plugin := range instance.Spec.Rabbitmq.CommunityPlugins {
+		catalog = append(catalog, PluginDefinition{
+			Name:         plugin.Name,
+			Ports:        plugin.Ports,
+			Dependencies: plugin.Dependencies,
+		})
+	}
+	return catalog
+}
+
+// EnabledPlugins returns the additional plugins of the RabbitmqCluster followed by their dependencies in the plugin catalog.
+// Dependencies are only enabled: their ports are not exposed unless they are listed as well.
+func EnabledPlugins(instance *rabbitmqv1beta1.RabbitmqCluster) []rabbitmqv1beta1.Plugin {
+	byName := map[rabbitmqv1beta1.Plugin]PluginDefinition{}
+	for _, plugin := range PluginCatalog(instance) {
+		byName[plugin.Name] = plugin
+	}
+
+	var plugins []rabbitmqv1beta1.Plugin
+	enabled := map[rabbitmqv1beta1.Plugin]bool{}
+	pending := instance.EnabledAdditionalPlugins()
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if enabled[name] {
+			continue
+		}
+		enabled[name] = true
+		plugins = append(plugins, name)
+		pending = append(pending, byName[name].Dependencies...)
+	}
+	return plugins
+}
+
+// ListedPlugins returns the catalog entries of the additional plugins of the RabbitmqCluster, in catalog order.
+// Dependencies of these plugins are not included.
+func ListedPlugins(instance *rabbitmqv1beta1.RabbitmqCluster) []PluginDefinition {
+	listed := map[rabbitmqv1beta1.Plugin]bool{}
+	for _, name := range instance.EnabledAdditionalPlugins() {
+		listed[name] = true
+	}
+
+	var plugins []PluginDefinition
+	for _, plugin := range PluginCatalog(instance) {
+		if listed[plugin.Name] {
+			plugins = append(plugins, plugin)
+		}
+	}
+	return plugins
+}
+
+// PluginPorts returns the ports of the listed plugins, either the plain or the TLS ones.
+// Ports shared by several plugins are returned once.
+func PluginPorts(instance *rabbitmqv1beta1.RabbitmqCluster, tls bool) []rabbitmqv1beta1.PluginPort {
+	var ports []rabbitmqv1beta1.PluginPort
+	seen := map[string]bool{}
+	for _, plugin := range ListedPlugins(instance) {
+		for _, port := range plugin.Ports {
+			if port.TLS != tls || seen[port.Name] {
+				continue
+			}
+			seen[port.Name] = true
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
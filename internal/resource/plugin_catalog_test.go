@@ -0,0 +1,137 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+)
+
+var _ = Describe("PluginCatalog", func() {
+	var instance *rabbitmqv1beta1.RabbitmqCluster
+
+	BeforeEach(func() {
+		instance = &rabbitmqv1beta1.RabbitmqCluster{}
+	})
+
+	pluginNames := func(plugins []resource.PluginDefinition) []rabbitmqv1beta1.Plugin {
+		var names []rabbitmqv1beta1.Plugin
+		for _, plugin := range plugins {
+			names = append(names, plugin.Name)
+		}
+		return names
+	}
+
+	portNames := func(ports []rabbitmqv1beta1.PluginPort) []string {
+		var names []string
+		for _, port := range ports {
+			names = append(names, port.Name)
+		}
+		return names
+	}
+
+	Context("EnabledPlugins", func() {
+		It("returns nothing when no additional plugins are enabled", func() {
+			Expect(resource.EnabledPlugins(instance)).To(BeEmpty())
+		})
+
+		It("appends the dependencies of the additional plugins", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_web_mqtt_examples", "rabbitmq_shovel"}
+
+			Expect(resource.EnabledPlugins(instance)).To(Equal([]rabbitmqv1beta1.Plugin{
+				"rabbitmq_web_mqtt_examples",
+				"rabbitmq_shovel",
+				"rabbitmq_web_mqtt",
+				"rabbitmq_mqtt",
+				"rabbitmq_web_dispatch",
+			}))
+		})
+
+		It("includes community plugins and their dependencies", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_custom"}
+			instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+				{
+					Name:         "rabbitmq_custom",
+					Dependencies: []rabbitmqv1beta1.Plugin{"rabbitmq_stream"},
+					Ports:        []rabbitmqv1beta1.PluginPort{{Name: "custom", Port: 4242}},
+				},
+			}
+
+			Expect(resource.EnabledPlugins(instance)).To(Equal([]rabbitmqv1beta1.Plugin{"rabbitmq_custom", "rabbitmq_stream"}))
+		})
+	})
+
+	Context("ListedPlugins", func() {
+		It("does not include dependencies", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_web_mqtt"}
+
+			Expect(pluginNames(resource.ListedPlugins(instance))).To(Equal([]rabbitmqv1beta1.Plugin{"rabbitmq_web_mqtt"}))
+		})
+
+		It("ignores plugins without a catalog entry", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_shovel", "rabbitmq_stomp"}
+
+			Expect(pluginNames(resource.ListedPlugins(instance))).To(Equal([]rabbitmqv1beta1.Plugin{"rabbitmq_stomp"}))
+		})
+	})
+
+	Context("PluginCatalog", func() {
+		It("lets community plugins replace built-in entries", func() {
+			instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+				{
+					Name:  "rabbitmq_mqtt",
+					Ports: []rabbitmqv1beta1.PluginPort{{Name: "mqtt", Port: 11883}},
+				},
+			}
+
+			var mqtt []resource.PluginDefinition
+			for _, plugin := range resource.PluginCatalog(instance) {
+				if plugin.Name == "rabbitmq_mqtt" {
+					mqtt = append(mqtt, plugin)
+				}
+			}
+			Expect(mqtt).To(HaveLen(1))
+			Expect(mqtt[0].Ports).To(ConsistOf(rabbitmqv1beta1.PluginPort{Name: "mqtt", Port: 11883}))
+		})
+	})
+
+	Context("PluginPorts", func() {
+		BeforeEach(func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{
+				"rabbitmq_web_stomp_examples",
+				"rabbitmq_web_mqtt_examples",
+			}
+		})
+
+		It("returns the plain ports once each", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = append(instance.Spec.Rabbitmq.AdditionalPlugins, "rabbitmq_web_mqtt", "rabbitmq_stomp")
+
+			Expect(portNames(resource.PluginPorts(instance, false))).To(Equal([]string{
+				"web-mqtt", "stomp", "web-examples",
+			}))
+		})
+
+		It("returns the TLS ports", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = append(instance.Spec.Rabbitmq.AdditionalPlugins, "rabbitmq_web_mqtt", "rabbitmq_stomp")
+
+			Expect(portNames(resource.PluginPorts(instance, true))).To(Equal([]string{
+				"web-mqtt-tls", "stomps",
+			}))
+		})
+
+		It("does not return the ports of dependencies", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_web_mqtt"}
+
+			Expect(portNames(resource.PluginPorts(instance, false))).To(Equal([]string{"web-mqtt"}))
+		})
+	})
+})
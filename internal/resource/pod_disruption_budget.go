@@ -0,0 +1,90 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	podDisruptionBudgetName = "server"
+)
+
+type PodDisruptionBudgetBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+}
+
+func (builder *RabbitmqResourceBuilder) PodDisruptionBudget() *PodDisruptionBudgetBuilder {
+	return &PodDisruptionBudgetBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+	}
+}
+
+func (builder *PodDisruptionBudgetBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *PodDisruptionBudgetBuilder) Build() (runtime.Object, error) {
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(podDisruptionBudgetName),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *PodDisruptionBudgetBuilder) Update(object runtime.Object) error {
+	pdb := object.(*policyv1beta1.PodDisruptionBudget)
+	pdb.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	pdb.Annotations = metadata.ReconcileAndFilterAnnotations(pdb.GetAnnotations(), builder.Instance.Annotations)
+
+	// a minority of the nodes may be unavailable at a time so that quorum queues keep a majority,
+	// but at least one so that a two node cluster does not block node drains
+	maxUnavailable := intstr.FromInt(1)
+	if minority := int((*builder.Instance.Spec.Replicas - 1) / 2); minority > 1 {
+		maxUnavailable = intstr.FromInt(minority)
+	}
+	pdb.Spec = policyv1beta1.PodDisruptionBudgetSpec{
+		MaxUnavailable: &maxUnavailable,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: metadata.LabelSelector(builder.Instance.Name),
+		},
+	}
+
+	if override := builder.Instance.Spec.Override.PodDisruptionBudget; override != nil {
+		if override.EmbeddedLabelsAnnotations != nil {
+			copyLabelsAnnotations(&pdb.ObjectMeta, *override.EmbeddedLabelsAnnotations)
+		}
+		// the budget is either minAvailable or maxUnavailable, so an overridden minAvailable replaces the default maxUnavailable
+		if override.Spec != nil && override.Spec.MinAvailable != nil {
+			pdb.Spec.MinAvailable = override.Spec.MinAvailable
+			pdb.Spec.MaxUnavailable = nil
+		} else if override.Spec != nil && override.Spec.MaxUnavailable != nil {
+			pdb.Spec.MinAvailable = nil
+			pdb.Spec.MaxUnavailable = override.Spec.MaxUnavailable
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, pdb, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
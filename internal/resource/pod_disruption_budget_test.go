@@ -0,0 +1,164 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("PodDisruptionBudget", func() {
+	var (
+		instance   rabbitmqv1beta1.RabbitmqCluster
+		pdbBuilder *resource.PodDisruptionBudgetBuilder
+		pdb        *policyv1beta1.PodDisruptionBudget
+		three      int32 = 3
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbit",
+				Namespace: "rabbit-namespace",
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: &three,
+			},
+		}
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		pdbBuilder = builder.PodDisruptionBudget()
+		obj, err := pdbBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		pdb = obj.(*policyv1beta1.PodDisruptionBudget)
+	})
+
+	Context("Build", func() {
+		It("generates a PodDisruptionBudget with the correct name and namespace", func() {
+			Expect(pdb.Name).To(Equal("rabbit-rabbitmq-server"))
+			Expect(pdb.Namespace).To(Equal("rabbit-namespace"))
+		})
+	})
+
+	Context("Update", func() {
+		It("allows one RabbitMQ Pod of three to be unavailable", func() {
+			Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+			maxUnavailable := intstr.FromInt(1)
+			Expect(pdb.Spec.MaxUnavailable).To(Equal(&maxUnavailable))
+			Expect(pdb.Spec.MinAvailable).To(BeNil())
+			Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "rabbit"}))
+		})
+
+		It("allows a minority of the RabbitMQ Pods to be unavailable", func() {
+			for replicas, expected := range map[int32]int{5: 2, 6: 2, 7: 3} {
+				replicas := replicas
+				instance.Spec.Replicas = &replicas
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+				Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(expected), "replicas: %d", replicas)
+			}
+		})
+
+		It("allows one RabbitMQ Pod of two to be unavailable so that node drains are not blocked", func() {
+			two := int32(2)
+			instance.Spec.Replicas = &two
+			Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+			Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+		})
+
+		It("sets the labels and annotations of the CR", func() {
+			instance.Labels = map[string]string{"foo": "bar"}
+			instance.Annotations = map[string]string{"my-annotation": "i-like-this"}
+			Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+			Expect(pdb.Labels).To(HaveKeyWithValue("foo", "bar"))
+			Expect(pdb.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "rabbit"))
+			Expect(pdb.Annotations).To(HaveKeyWithValue("my-annotation", "i-like-this"))
+		})
+
+		It("sets the owner reference", func() {
+			Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+			Expect(pdb.OwnerReferences).To(HaveLen(1))
+			Expect(pdb.OwnerReferences[0].Name).To(Equal("rabbit"))
+		})
+
+		When("the budget is overridden", func() {
+			It("uses the budget from the override", func() {
+				minAvailable := intstr.FromString("60%")
+				instance.Spec.Override.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudget{
+					EmbeddedLabelsAnnotations: &rabbitmqv1beta1.EmbeddedLabelsAnnotations{
+						Labels: map[string]string{"override": "label"},
+					},
+					Spec: &rabbitmqv1beta1.PodDisruptionBudgetSpec{
+						MinAvailable: &minAvailable,
+					},
+				}
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+				Expect(pdb.Spec.MinAvailable).To(Equal(&minAvailable))
+				Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+				Expect(pdb.Labels).To(HaveKeyWithValue("override", "label"))
+			})
+
+			It("clears the default maxUnavailable of an existing budget when the override sets minAvailable", func() {
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+				Expect(pdb.Spec.MaxUnavailable).NotTo(BeNil())
+
+				minAvailable := intstr.FromInt(2)
+				instance.Spec.Override.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudget{
+					Spec: &rabbitmqv1beta1.PodDisruptionBudgetSpec{
+						MinAvailable: &minAvailable,
+					},
+				}
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+				Expect(pdb.Spec.MinAvailable).To(Equal(&minAvailable))
+				Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+			})
+
+			It("uses maxUnavailable from the override", func() {
+				maxUnavailable := intstr.FromString("50%")
+				instance.Spec.Override.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudget{
+					Spec: &rabbitmqv1beta1.PodDisruptionBudgetSpec{
+						MaxUnavailable: &maxUnavailable,
+					},
+				}
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+				Expect(pdb.Spec.MaxUnavailable).To(Equal(&maxUnavailable))
+				Expect(pdb.Spec.MinAvailable).To(BeNil())
+			})
+
+			It("keeps the default budget when the override spec is empty", func() {
+				instance.Spec.Override.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudget{
+					Spec: &rabbitmqv1beta1.PodDisruptionBudgetSpec{},
+				}
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+
+				Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+			})
+		})
+	})
+})
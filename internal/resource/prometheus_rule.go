@@ -0,0 +1,120 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	prometheusRuleName = "prometheus-rule"
+	PrometheusRuleKind = "PrometheusRule"
+)
+
+// PrometheusRuleBuilder builds a PrometheusRule with alerts on the metrics exposed by rabbitmq_prometheus.
+type PrometheusRuleBuilder struct {
+	Instance *rabbitmqv1beta1.RabbitmqCluster
+	Scheme   *runtime.Scheme
+}
+
+func (builder *RabbitmqResourceBuilder) PrometheusRule() *PrometheusRuleBuilder {
+	return &PrometheusRuleBuilder{
+		Instance: builder.Instance,
+		Scheme:   builder.Scheme,
+	}
+}
+
+func (builder *PrometheusRuleBuilder) UpdateRequiresStsRestart() bool {
+	return false
+}
+
+func (builder *PrometheusRuleBuilder) Build() (runtime.Object, error) {
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(MonitoringGroupVersion.WithKind(PrometheusRuleKind))
+	rule.SetName(builder.Instance.ChildResourceName(prometheusRuleName))
+	rule.SetNamespace(builder.Instance.Namespace)
+	return rule, nil
+}
+
+func (builder *PrometheusRuleBuilder) Update(object runtime.Object) error {
+	rule := object.(*unstructured.Unstructured)
+	rule.SetLabels(monitoringLabels(builder.Instance))
+
+	rules := []interface{}{}
+	for _, alert := range builder.alerts() {
+		rules = append(rules, alert)
+	}
+	rule.Object["spec"] = map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  builder.Instance.ChildResourceName("alerts"),
+				"rules": rules,
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, rule, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+
+	return nil
+}
+
+func (builder *PrometheusRuleBuilder) alerts() []map[string]interface{} {
+	// every series scraped from the StatefulSet Pods of this RabbitmqCluster
+	selector := fmt.Sprintf(`namespace="%s",pod=~"%s-[0-9]+"`, builder.Instance.Namespace, builder.Instance.ChildResourceName("server"))
+	var replicas int32 = 1
+	if builder.Instance.Spec.Replicas != nil {
+		replicas = *builder.Instance.Spec.Replicas
+	}
+
+	return []map[string]interface{}{
+		builder.alert("RabbitmqNodeDown", "critical", "5m",
+			fmt.Sprintf(`sum(up{%[1]s}) < %[2]d or absent(up{%[1]s})`, selector, replicas),
+			"Fewer RabbitMQ nodes are up than the RabbitmqCluster has replicas."),
+		builder.alert("RabbitmqNetworkPartition", "critical", "5m",
+			// without any connected node the count has no series, so it falls back to 0
+			fmt.Sprintf(`(count(erlang_vm_dist_node_state{%s} == 3) or vector(0)) < %d`, selector, replicas*(replicas-1)),
+			"Some RabbitMQ nodes cannot reach each other over Erlang distribution; the cluster may be partitioned."),
+		builder.alert("RabbitmqMemoryAlarm", "critical", "1m",
+			fmt.Sprintf(`max(rabbitmq_alarms_memory_used_watermark{%s}) > 0`, selector),
+			"A RabbitMQ node has raised a memory alarm and is blocking publishers."),
+		builder.alert("RabbitmqDiskAlarm", "critical", "1m",
+			fmt.Sprintf(`max(rabbitmq_alarms_free_disk_space_watermark{%s}) > 0`, selector),
+			"A RabbitMQ node has raised a disk alarm and is blocking publishers."),
+		builder.alert("RabbitmqUnroutableMessages", "warning", "5m",
+			fmt.Sprintf(`sum(rate(rabbitmq_channel_messages_unroutable_dropped_total{%[1]s}[5m])) + sum(rate(rabbitmq_channel_messages_unroutable_returned_total{%[1]s}[5m])) > 0`, selector),
+			"Messages published to the RabbitmqCluster are not routed to any queue."),
+		builder.alert("RabbitmqFileDescriptorsNearLimit", "warning", "10m",
+			fmt.Sprintf(`max(rabbitmq_process_open_fds{%[1]s} / rabbitmq_process_max_fds{%[1]s}) > 0.8`, selector),
+			"A RabbitMQ node is using more than 80% of its available file descriptors."),
+	}
+}
+
+func (builder *PrometheusRuleBuilder) alert(name, severity, duration, expr, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": name,
+		"expr":  expr,
+		"for":   duration,
+		"labels": map[string]interface{}{
+			"severity":         severity,
+			"rabbitmq_cluster": builder.Instance.Name,
+		},
+		"annotations": map[string]interface{}{
+			"summary":     fmt.Sprintf("%s on RabbitmqCluster %s/%s", name, builder.Instance.Namespace, builder.Instance.Name),
+			"description": description,
+		},
+	}
+}
@@ -0,0 +1,112 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("PrometheusRule", func() {
+	var (
+		instance    rabbitmqv1beta1.RabbitmqCluster
+		ruleBuilder *resource.PrometheusRuleBuilder
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		three := int32(3)
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbit",
+				Namespace: "rabbit-namespace",
+			},
+		}
+		instance.Spec.Replicas = &three
+		instance.Spec.Monitoring = &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{
+			Labels: map[string]string{"release": "prometheus"},
+		}
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		ruleBuilder = builder.PrometheusRule()
+	})
+
+	It("builds a PrometheusRule with the correct name and namespace", func() {
+		obj, err := ruleBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		rule := obj.(*unstructured.Unstructured)
+
+		Expect(rule.GetAPIVersion()).To(Equal("monitoring.coreos.com/v1"))
+		Expect(rule.GetKind()).To(Equal("PrometheusRule"))
+		Expect(rule.GetName()).To(Equal("rabbit-rabbitmq-prometheus-rule"))
+		Expect(rule.GetNamespace()).To(Equal("rabbit-namespace"))
+	})
+
+	Context("Update", func() {
+		var rule *unstructured.Unstructured
+
+		BeforeEach(func() {
+			obj, err := ruleBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			rule = obj.(*unstructured.Unstructured)
+			Expect(ruleBuilder.Update(rule)).To(Succeed())
+		})
+
+		It("sets the labels of spec.monitoring", func() {
+			Expect(rule.GetLabels()).To(HaveKeyWithValue("release", "prometheus"))
+		})
+
+		It("sets the owner reference", func() {
+			Expect(rule.GetOwnerReferences()).To(HaveLen(1))
+			Expect(rule.GetOwnerReferences()[0].Name).To(Equal("rabbit"))
+		})
+
+		It("defines the curated alerts scoped to the cluster's Pods", func() {
+			groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(HaveLen(1))
+			rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+
+			var names []string
+			for _, r := range rules {
+				alert := r.(map[string]interface{})
+				names = append(names, alert["alert"].(string))
+				Expect(alert["expr"]).To(ContainSubstring(`namespace="rabbit-namespace",pod=~"rabbit-rabbitmq-server-[0-9]+"`))
+				Expect(alert["labels"]).To(HaveKeyWithValue("rabbitmq_cluster", "rabbit"))
+			}
+			Expect(names).To(ConsistOf(
+				"RabbitmqNodeDown",
+				"RabbitmqNetworkPartition",
+				"RabbitmqMemoryAlarm",
+				"RabbitmqDiskAlarm",
+				"RabbitmqUnroutableMessages",
+				"RabbitmqFileDescriptorsNearLimit",
+			))
+		})
+
+		It("expects every node to be up and fully connected", func() {
+			groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+			rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+
+			Expect(rules[0].(map[string]interface{})["expr"]).To(ContainSubstring("< 3"))
+			Expect(rules[1].(map[string]interface{})["expr"]).To(ContainSubstring("or vector(0)) < 6"))
+		})
+	})
+})
@@ -75,7 +75,7 @@ func (builder *RabbitmqPluginsConfigMapBuilder) Update(object runtime.Object) er
 	if configMap.Data == nil {
 		configMap.Data = make(map[string]string)
 	}
-	configMap.Data["enabled_plugins"] = desiredPluginsAsString(builder.Instance.Spec.Rabbitmq.AdditionalPlugins)
+	configMap.Data["enabled_plugins"] = desiredPluginsAsString(EnabledPlugins(builder.Instance))
 	return nil
 }
 
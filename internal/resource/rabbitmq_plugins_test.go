@@ -160,6 +160,39 @@ var _ = Describe("RabbitMQPlugins", func() {
 					})
 				})
 			})
+
+			When("community plugins with a source are provided in instance spec", func() {
+				It("enables them after the additionalPlugins", func() {
+					builder.Instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_shovel"}
+					builder.Instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+						{Name: "rabbitmq_in_image"},
+						{Name: "rabbitmq_message_timestamp", Source: &rabbitmqv1beta1.CommunityPluginSource{Image: "plugins"}},
+					}
+
+					Expect(configMapBuilder.Update(configMap)).To(Succeed())
+					Expect(configMap.Data).To(HaveKeyWithValue("enabled_plugins", "["+
+						"rabbitmq_peer_discovery_k8s,"+
+						"rabbitmq_prometheus,"+
+						"rabbitmq_management,"+
+						"rabbitmq_shovel,"+
+						"rabbitmq_message_timestamp]."))
+				})
+			})
+
+			When("plugins with dependencies are provided in instance spec", func() {
+				It("enables the dependencies after the additionalPlugins", func() {
+					builder.Instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_web_stomp"}
+
+					Expect(configMapBuilder.Update(configMap)).To(Succeed())
+					Expect(configMap.Data).To(HaveKeyWithValue("enabled_plugins", "["+
+						"rabbitmq_peer_discovery_k8s,"+
+						"rabbitmq_prometheus,"+
+						"rabbitmq_management,"+
+						"rabbitmq_web_stomp,"+
+						"rabbitmq_stomp,"+
+						"rabbitmq_web_dispatch]."))
+				})
+			})
 		})
 	})
 })
@@ -17,6 +17,14 @@ import (
 type RabbitmqResourceBuilder struct {
 	Instance *rabbitmqv1beta1.RabbitmqCluster
 	Scheme   *runtime.Scheme
+	// Whether the monitoring.coreos.com CRDs of the Prometheus Operator are installed in the Kubernetes cluster
+	MonitoringCRDsInstalled bool
+	// Labels of the operator Pod, allowed to reach the management API through the NetworkPolicy
+	OperatorPodLabels map[string]string
+	// Whether the HTTPRoute CRD of the Gateway API is installed in the Kubernetes cluster
+	GatewayAPICRDsInstalled bool
+	// Image of the setup-container of the RabbitMQ Pods, which is the image of the operator
+	SetupContainerImage string
 }
 
 type ResourceBuilder interface {
@@ -26,7 +34,11 @@ type ResourceBuilder interface {
 }
 
 func (builder *RabbitmqResourceBuilder) ResourceBuilders() ([]ResourceBuilder, error) {
-	return []ResourceBuilder{
+	if err := validateAdditionalServices(builder.Instance); err != nil {
+		return nil, err
+	}
+
+	builders := []ResourceBuilder{
 		builder.HeadlessService(),
 		builder.ClientService(),
 		builder.ErlangCookie(),
@@ -37,5 +49,42 @@ func (builder *RabbitmqResourceBuilder) ResourceBuilders() ([]ResourceBuilder, e
 		builder.Role(),
 		builder.RoleBinding(),
 		builder.StatefulSet(),
-	}, nil
+	}
+
+	// a single node cluster has no quorum to protect from voluntary disruptions
+	if builder.Instance.Spec.Replicas != nil && *builder.Instance.Spec.Replicas > 1 {
+		builders = append(builders, builder.PodDisruptionBudget())
+	}
+
+	if builder.Instance.PerPodServiceEnabled() && builder.Instance.Spec.Replicas != nil {
+		for ordinal := int32(0); ordinal < *builder.Instance.Spec.Replicas; ordinal++ {
+			builders = append(builders, builder.PerPodService(ordinal))
+		}
+	}
+
+	if builder.Instance.NetworkPolicyEnabled() {
+		builders = append(builders, builder.NetworkPolicy())
+	}
+
+	for _, service := range builder.Instance.Spec.AdditionalServices {
+		builders = append(builders, builder.AdditionalService(service))
+	}
+
+	if builder.Instance.ManagementIngressEnabled() {
+		builders = append(builders, builder.ManagementService())
+		if !builder.Instance.ManagementHTTPRouteEnabled() {
+			builders = append(builders, builder.ManagementIngress())
+		} else if builder.GatewayAPICRDsInstalled {
+			builders = append(builders, builder.ManagementHTTPRoute())
+		}
+	}
+
+	if builder.Instance.MonitoringEnabled() && builder.MonitoringCRDsInstalled {
+		builders = append(builders, builder.Monitor())
+		if !builder.Instance.Spec.Monitoring.DisableAlerts {
+			builders = append(builders, builder.PrometheusRule())
+		}
+	}
+
+	return builders, nil
 }
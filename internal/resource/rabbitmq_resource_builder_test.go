@@ -68,5 +68,136 @@ var _ = Describe("RabbitmqResourceBuilder", func() {
 				Expect(resourceBuilders[i]).To(BeAssignableToTypeOf(expectedBuildersInOrder[i]))
 			}
 		})
+
+		It("appends a PodDisruptionBudget builder for multi-node clusters", func() {
+			three := int32(3)
+			instance.Spec.Replicas = &three
+			defer func() { instance.Spec.Replicas = nil }()
+
+			resourceBuilders, err := builder.ResourceBuilders()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resourceBuilders).To(HaveLen(11))
+			Expect(resourceBuilders[10]).To(BeAssignableToTypeOf(&PodDisruptionBudgetBuilder{}))
+		})
+
+		It("does not create a PodDisruptionBudget for single node clusters", func() {
+			one := int32(1)
+			instance.Spec.Replicas = &one
+			defer func() { instance.Spec.Replicas = nil }()
+
+			resourceBuilders, err := builder.ResourceBuilders()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resourceBuilders).To(HaveLen(10))
+		})
+
+		It("appends a per-pod Service builder for every replica when enabled", func() {
+			three := int32(3)
+			instance.Spec.Replicas = &three
+			instance.Spec.PerPodService = &rabbitmqv1beta1.RabbitmqClusterPerPodServiceSpec{}
+			defer func() {
+				instance.Spec.Replicas = nil
+				instance.Spec.PerPodService = nil
+			}()
+
+			resourceBuilders, err := builder.ResourceBuilders()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resourceBuilders).To(HaveLen(14))
+			for i, ordinal := range []int32{0, 1, 2} {
+				Expect(resourceBuilders[11+i]).To(BeAssignableToTypeOf(&PerPodServiceBuilder{}))
+				Expect(resourceBuilders[11+i].(*PerPodServiceBuilder).Ordinal).To(Equal(ordinal))
+			}
+		})
+
+		It("appends a NetworkPolicy builder when enabled", func() {
+			instance.Spec.NetworkPolicy = &rabbitmqv1beta1.RabbitmqClusterNetworkPolicySpec{}
+			defer func() { instance.Spec.NetworkPolicy = nil }()
+
+			resourceBuilders, err := builder.ResourceBuilders()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resourceBuilders).To(HaveLen(11))
+			Expect(resourceBuilders[10]).To(BeAssignableToTypeOf(&NetworkPolicyBuilder{}))
+		})
+
+		When("the management ingress is enabled", func() {
+			BeforeEach(func() {
+				instance.Spec.Management = &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+					Ingress: &rabbitmqv1beta1.RabbitmqClusterManagementIngressSpec{Host: "rabbitmq.example.com"},
+				}
+			})
+
+			AfterEach(func() {
+				instance.Spec.Management = nil
+			})
+
+			It("appends the management Service and Ingress builders", func() {
+				resourceBuilders, err := builder.ResourceBuilders()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resourceBuilders).To(HaveLen(12))
+				Expect(resourceBuilders[10]).To(BeAssignableToTypeOf(&ManagementServiceBuilder{}))
+				Expect(resourceBuilders[11]).To(BeAssignableToTypeOf(&ManagementIngressBuilder{}))
+			})
+
+			It("appends the HTTPRoute builder instead of the Ingress builder when the Gateway API CRDs are installed", func() {
+				instance.Spec.Management.Ingress.Kind = "HTTPRoute"
+				builder.GatewayAPICRDsInstalled = true
+				resourceBuilders, err := builder.ResourceBuilders()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resourceBuilders).To(HaveLen(12))
+				Expect(resourceBuilders[10]).To(BeAssignableToTypeOf(&ManagementServiceBuilder{}))
+				Expect(resourceBuilders[11]).To(BeAssignableToTypeOf(&ManagementHTTPRouteBuilder{}))
+			})
+
+			It("omits the HTTPRoute builder when the Gateway API CRDs are not installed", func() {
+				instance.Spec.Management.Ingress.Kind = "HTTPRoute"
+				resourceBuilders, err := builder.ResourceBuilders()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resourceBuilders).To(HaveLen(11))
+				Expect(resourceBuilders[10]).To(BeAssignableToTypeOf(&ManagementServiceBuilder{}))
+			})
+		})
+
+		When("monitoring is enabled", func() {
+			BeforeEach(func() {
+				instance.Spec.Monitoring = &rabbitmqv1beta1.RabbitmqClusterMonitoringSpec{}
+			})
+
+			AfterEach(func() {
+				instance.Spec.Monitoring = nil
+			})
+
+			It("appends the monitor and PrometheusRule builders when the CRDs are installed", func() {
+				builder.MonitoringCRDsInstalled = true
+				resourceBuilders, err := builder.ResourceBuilders()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resourceBuilders).To(HaveLen(12))
+				Expect(resourceBuilders[10]).To(BeAssignableToTypeOf(&MonitorBuilder{}))
+				Expect(resourceBuilders[11]).To(BeAssignableToTypeOf(&PrometheusRuleBuilder{}))
+			})
+
+			It("omits the PrometheusRule builder when alerts are disabled", func() {
+				builder.MonitoringCRDsInstalled = true
+				instance.Spec.Monitoring.DisableAlerts = true
+				resourceBuilders, err := builder.ResourceBuilders()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resourceBuilders).To(HaveLen(11))
+				Expect(resourceBuilders[10]).To(BeAssignableToTypeOf(&MonitorBuilder{}))
+			})
+
+			It("omits the monitoring builders when the CRDs are not installed", func() {
+				resourceBuilders, err := builder.ResourceBuilders()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(resourceBuilders).To(HaveLen(10))
+			})
+		})
 	})
 })
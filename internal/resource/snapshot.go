@@ -0,0 +1,146 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const VolumeSnapshotKind = "VolumeSnapshot"
+
+// VolumeSnapshotGroupVersion is the API group version of the CSI snapshot CRDs
+var VolumeSnapshotGroupVersion = schema.GroupVersion{Group: "snapshot.storage.k8s.io", Version: "v1"}
+
+// PersistenceClaimName returns the name of the persistence PersistentVolumeClaim the StatefulSet creates for the given Pod
+func PersistenceClaimName(podName string) string {
+	return "persistence-" + podName
+}
+
+// VolumeSnapshot builds the VolumeSnapshot of a node of the RabbitmqCluster snapshotted by snapshot.
+// CSI snapshot types are not vendored, so the resource is built as unstructured.
+func VolumeSnapshot(snapshot *rabbitmqv1beta1.RabbitmqSnapshot, volume rabbitmqv1beta1.RabbitmqSnapshotVolume, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetGroupVersionKind(VolumeSnapshotGroupVersion.WithKind(VolumeSnapshotKind))
+	volumeSnapshot.SetName(volume.VolumeSnapshotName)
+	volumeSnapshot.SetNamespace(snapshot.Namespace)
+	volumeSnapshot.SetLabels(metadata.GetLabels(snapshot.Spec.RabbitmqClusterReference.Name, snapshot.Labels))
+	volumeSnapshot.SetAnnotations(metadata.ReconcileAndFilterAnnotations(map[string]string{}, snapshot.Annotations))
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": volume.PersistentVolumeClaimName,
+		},
+	}
+	if snapshot.Spec.VolumeSnapshotClassName != nil {
+		spec["volumeSnapshotClassName"] = *snapshot.Spec.VolumeSnapshotClassName
+	}
+	volumeSnapshot.Object["spec"] = spec
+
+	if err := controllerutil.SetControllerReference(snapshot, volumeSnapshot, scheme); err != nil {
+		return nil, fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return volumeSnapshot, nil
+}
+
+// DefaultUserSecretName returns the name of the copy of the default user Secret kept by the given RabbitmqSnapshot
+func DefaultUserSecretName(snapshot *rabbitmqv1beta1.RabbitmqSnapshot) string {
+	return snapshot.Name + "-default-user"
+}
+
+// DefaultUserSecret builds the copy of the default user Secret of the RabbitmqCluster snapshotted by snapshot.
+// It is owned by the RabbitmqSnapshot, so that the credentials outlive the RabbitmqCluster whose users are held in the VolumeSnapshots.
+func DefaultUserSecret(snapshot *rabbitmqv1beta1.RabbitmqSnapshot, adminSecret *corev1.Secret, scheme *runtime.Scheme) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        DefaultUserSecretName(snapshot),
+			Namespace:   snapshot.Namespace,
+			Labels:      metadata.GetLabels(snapshot.Spec.RabbitmqClusterReference.Name, snapshot.Labels),
+			Annotations: metadata.ReconcileAndFilterAnnotations(map[string]string{}, snapshot.Annotations),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"username": adminSecret.Data["username"],
+			"password": adminSecret.Data["password"],
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(snapshot, secret, scheme); err != nil {
+		return nil, fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return secret, nil
+}
+
+// RestoredAdminSecret returns the default user Secret of the RabbitmqCluster, holding the credentials kept by the RabbitmqSnapshot it is restored from.
+// It is created before the other child resources, instead of a Secret with new random credentials unknown to the restored RabbitMQ.
+func (builder *RabbitmqResourceBuilder) RestoredAdminSecret(defaultUser *corev1.Secret) (*corev1.Secret, error) {
+	secretBuilder := builder.AdminSecret()
+	obj, err := secretBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+	secret := obj.(*corev1.Secret)
+	if err := secretBuilder.Update(secret); err != nil {
+		return nil, err
+	}
+	secret.Data = map[string][]byte{
+		"username": defaultUser.Data["username"],
+		"password": defaultUser.Data["password"],
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, secret, builder.Scheme); err != nil {
+		return nil, fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return secret, nil
+}
+
+// RestoredPersistentVolumeClaims returns the persistence PersistentVolumeClaim of each node, provisioned from the VolumeSnapshot taken of the same node.
+// They are created before the StatefulSet, which then uses them instead of creating empty claims from its volume claim template.
+func (builder *RabbitmqResourceBuilder) RestoredPersistentVolumeClaims(snapshot *rabbitmqv1beta1.RabbitmqSnapshot) ([]*corev1.PersistentVolumeClaim, error) {
+	if !builder.Instance.PersistenceEnabled() {
+		return nil, fmt.Errorf("spec.persistence.restoreFrom requires persistence to be enabled")
+	}
+	// RabbitMQ stores its data in a directory named after the node, which includes the name of the RabbitmqCluster
+	if snapshot.Spec.RabbitmqClusterReference.Name != builder.Instance.Name {
+		return nil, fmt.Errorf("RabbitmqSnapshot %s was taken from RabbitmqCluster %s, and can only be restored to a RabbitmqCluster of the same name",
+			snapshot.Name, snapshot.Spec.RabbitmqClusterReference.Name)
+	}
+	if snapshot.Status.Replicas != *builder.Instance.Spec.Replicas {
+		return nil, fmt.Errorf("RabbitmqSnapshot %s was taken from %d nodes, and can only be restored to a RabbitmqCluster of %d replicas",
+			snapshot.Name, snapshot.Status.Replicas, snapshot.Status.Replicas)
+	}
+
+	templates, err := persistentVolumeClaim(builder.Instance, builder.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	var pvcs []*corev1.PersistentVolumeClaim
+	for _, volume := range snapshot.Status.VolumeSnapshots {
+		apiGroup := VolumeSnapshotGroupVersion.Group
+		pvc := templates[0].DeepCopy()
+		pvc.Name = volume.PersistentVolumeClaimName
+		pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: &apiGroup,
+			Kind:     VolumeSnapshotKind,
+			Name:     volume.VolumeSnapshotName,
+		}
+		pvcs = append(pvcs, pvc)
+	}
+	return pvcs, nil
+}
@@ -0,0 +1,192 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Snapshot", func() {
+	var (
+		scheme   *runtime.Scheme
+		snapshot *rabbitmqv1beta1.RabbitmqSnapshot
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		snapshot = &rabbitmqv1beta1.RabbitmqSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nightly",
+				Namespace: "foo-namespace",
+			},
+			Spec: rabbitmqv1beta1.RabbitmqSnapshotSpec{
+				RabbitmqClusterReference: rabbitmqv1beta1.RabbitmqClusterReference{Name: "foo"},
+			},
+			Status: rabbitmqv1beta1.RabbitmqSnapshotStatus{
+				Phase:      rabbitmqv1beta1.CompletedRabbitmqSnapshotPhase,
+				Replicas:   1,
+				ReadyToUse: true,
+				VolumeSnapshots: []rabbitmqv1beta1.RabbitmqSnapshotVolume{{
+					Node:                      "foo-rabbitmq-server-0",
+					PersistentVolumeClaimName: "persistence-foo-rabbitmq-server-0",
+					VolumeSnapshotName:        "nightly-0",
+					ReadyToUse:                true,
+				}},
+			},
+		}
+	})
+
+	Context("VolumeSnapshot", func() {
+		It("snapshots the persistence PersistentVolumeClaim of the node", func() {
+			volumeSnapshot, err := resource.VolumeSnapshot(snapshot, snapshot.Status.VolumeSnapshots[0], scheme)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(volumeSnapshot.GetAPIVersion()).To(Equal("snapshot.storage.k8s.io/v1"))
+			Expect(volumeSnapshot.GetKind()).To(Equal("VolumeSnapshot"))
+			Expect(volumeSnapshot.GetName()).To(Equal("nightly-0"))
+			Expect(volumeSnapshot.GetNamespace()).To(Equal("foo-namespace"))
+			Expect(volumeSnapshot.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/name", "foo"))
+
+			source, _, _ := unstructured.NestedString(volumeSnapshot.Object, "spec", "source", "persistentVolumeClaimName")
+			Expect(source).To(Equal("persistence-foo-rabbitmq-server-0"))
+			_, found, _ := unstructured.NestedString(volumeSnapshot.Object, "spec", "volumeSnapshotClassName")
+			Expect(found).To(BeFalse())
+		})
+
+		It("uses the VolumeSnapshotClass of the RabbitmqSnapshot", func() {
+			className := "csi-hostpath-snapclass"
+			snapshot.Spec.VolumeSnapshotClassName = &className
+
+			volumeSnapshot, err := resource.VolumeSnapshot(snapshot, snapshot.Status.VolumeSnapshots[0], scheme)
+			Expect(err).NotTo(HaveOccurred())
+			class, _, _ := unstructured.NestedString(volumeSnapshot.Object, "spec", "volumeSnapshotClassName")
+			Expect(class).To(Equal(className))
+		})
+
+		It("is owned by the RabbitmqSnapshot", func() {
+			volumeSnapshot, err := resource.VolumeSnapshot(snapshot, snapshot.Status.VolumeSnapshots[0], scheme)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeSnapshot.GetOwnerReferences()).To(HaveLen(1))
+			Expect(volumeSnapshot.GetOwnerReferences()[0].Kind).To(Equal("RabbitmqSnapshot"))
+			Expect(volumeSnapshot.GetOwnerReferences()[0].Name).To(Equal("nightly"))
+		})
+	})
+
+	Context("DefaultUserSecret", func() {
+		It("copies the credentials of the default user, and is owned by the RabbitmqSnapshot", func() {
+			adminSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo-rabbitmq-admin", Namespace: "foo-namespace"},
+				Data: map[string][]byte{
+					"username": []byte("user"),
+					"password": []byte("pass"),
+				},
+			}
+
+			secret, err := resource.DefaultUserSecret(snapshot, adminSecret, scheme)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret.Name).To(Equal("nightly-default-user"))
+			Expect(secret.Namespace).To(Equal("foo-namespace"))
+			Expect(secret.Data).To(Equal(map[string][]byte{
+				"username": []byte("user"),
+				"password": []byte("pass"),
+			}))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].Kind).To(Equal("RabbitmqSnapshot"))
+			Expect(secret.OwnerReferences[0].Name).To(Equal("nightly"))
+		})
+	})
+
+	Context("RestoredAdminSecret", func() {
+		It("is the default user Secret of the RabbitmqCluster, with the credentials kept by the RabbitmqSnapshot", func() {
+			instance := generateRabbitmqCluster()
+			builder := &resource.RabbitmqResourceBuilder{
+				Instance: &instance,
+				Scheme:   scheme,
+			}
+			defaultUser := &corev1.Secret{
+				Data: map[string][]byte{
+					"username": []byte("user"),
+					"password": []byte("pass"),
+				},
+			}
+
+			secret, err := builder.RestoredAdminSecret(defaultUser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret.Name).To(Equal("foo-rabbitmq-admin"))
+			Expect(secret.Namespace).To(Equal("foo-namespace"))
+			Expect(secret.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "foo"))
+			Expect(secret.Data).To(Equal(defaultUser.Data))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].Name).To(Equal("foo"))
+		})
+	})
+
+	Context("RestoredPersistentVolumeClaims", func() {
+		var (
+			instance rabbitmqv1beta1.RabbitmqCluster
+			builder  *resource.RabbitmqResourceBuilder
+		)
+
+		BeforeEach(func() {
+			instance = generateRabbitmqCluster()
+			builder = &resource.RabbitmqResourceBuilder{
+				Instance: &instance,
+				Scheme:   scheme,
+			}
+		})
+
+		It("provisions the claim of each node from its VolumeSnapshot", func() {
+			pvcs, err := builder.RestoredPersistentVolumeClaims(snapshot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pvcs).To(HaveLen(1))
+
+			pvc := pvcs[0]
+			Expect(pvc.Name).To(Equal("persistence-foo-rabbitmq-server-0"))
+			Expect(pvc.Namespace).To(Equal("foo-namespace"))
+			Expect(pvc.Spec.DataSource).NotTo(BeNil())
+			Expect(*pvc.Spec.DataSource.APIGroup).To(Equal("snapshot.storage.k8s.io"))
+			Expect(pvc.Spec.DataSource.Kind).To(Equal("VolumeSnapshot"))
+			Expect(pvc.Spec.DataSource.Name).To(Equal("nightly-0"))
+			Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(k8sresource.MustParse("10Gi")))
+			Expect(pvc.OwnerReferences).To(HaveLen(1))
+			Expect(pvc.OwnerReferences[0].Name).To(Equal("foo"))
+		})
+
+		It("fails for a RabbitmqCluster of another name", func() {
+			instance.Name = "bar"
+			_, err := builder.RestoredPersistentVolumeClaims(snapshot)
+			Expect(err).To(MatchError(ContainSubstring("same name")))
+		})
+
+		It("fails for a RabbitmqCluster of another number of replicas", func() {
+			three := int32(3)
+			instance.Spec.Replicas = &three
+			_, err := builder.RestoredPersistentVolumeClaims(snapshot)
+			Expect(err).To(MatchError(ContainSubstring("1 replicas")))
+		})
+
+		It("fails when persistence is disabled", func() {
+			instance.Spec.Persistence.Enabled = new(bool)
+			_, err := builder.RestoredPersistentVolumeClaims(snapshot)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -10,8 +10,10 @@
 package resource
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	"github.com/rabbitmq/cluster-operator/internal/metadata"
@@ -27,22 +29,31 @@ import (
 )
 
 const (
-	defaultGracePeriodTimeoutSeconds int64  = 60 * 60 * 24 * 7
-	initContainerCPU                 string = "100m"
-	initContainerMemory              string = "500Mi"
-	DeletionMarker                   string = "skipPreStopChecks"
+	initContainerCPU    string = "100m"
+	initContainerMemory string = "500Mi"
+	DeletionMarker      string = "skipPreStopChecks"
+
+	seccompPodAnnotation = "seccomp.security.alpha.kubernetes.io/pod"
+	// path of the setup-container binary in the operator image
+	setupContainerBinary = "/setup-container"
+	// RestartAtAnnotation is set on the Pod template to restart the RabbitMQ Pods
+	RestartAtAnnotation = "rabbitmq.com/restartAt"
+	// hash of the Pod template without the setup-container image, see keepSetupContainerImage
+	podTemplateHashAnnotation = "rabbitmq.com/podTemplateHash"
 )
 
 func (builder *RabbitmqResourceBuilder) StatefulSet() *StatefulSetBuilder {
 	return &StatefulSetBuilder{
-		Instance: builder.Instance,
-		Scheme:   builder.Scheme,
+		Instance:            builder.Instance,
+		Scheme:              builder.Scheme,
+		SetupContainerImage: builder.SetupContainerImage,
 	}
 }
 
 type StatefulSetBuilder struct {
-	Instance *rabbitmqv1beta1.RabbitmqCluster
-	Scheme   *runtime.Scheme
+	Instance            *rabbitmqv1beta1.RabbitmqCluster
+	Scheme              *runtime.Scheme
+	SetupContainerImage string
 }
 
 func (builder *StatefulSetBuilder) UpdateRequiresStsRestart() bool {
@@ -101,7 +112,24 @@ func (builder *StatefulSetBuilder) Build() (runtime.Object, error) {
 	return sts, nil
 }
 
+func hasVolumeClaimTemplate(sts *appsv1.StatefulSet, name string) bool {
+	for _, claim := range sts.Spec.VolumeClaimTemplates {
+		if claim.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func persistentVolumeClaim(instance *rabbitmqv1beta1.RabbitmqCluster, scheme *runtime.Scheme) ([]corev1.PersistentVolumeClaim, error) {
+	additionalPVCs, err := additionalVolumeClaims(instance, scheme)
+	if err != nil {
+		return nil, err
+	}
+	if !instance.PersistenceEnabled() {
+		return additionalPVCs, nil
+	}
+
 	pvc := corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        "persistence",
@@ -125,7 +153,7 @@ func persistentVolumeClaim(instance *rabbitmqv1beta1.RabbitmqCluster, scheme *ru
 	}
 	disableBlockOwnerDeletion(pvc)
 
-	return []corev1.PersistentVolumeClaim{pvc}, nil
+	return append([]corev1.PersistentVolumeClaim{pvc}, additionalPVCs...), nil
 }
 
 // required for OpenShift compatibility, see https://github.com/rabbitmq/cluster-operator/issues/234
@@ -138,6 +166,33 @@ func disableBlockOwnerDeletion(pvc corev1.PersistentVolumeClaim) {
 
 func (builder *StatefulSetBuilder) Update(object runtime.Object) error {
 	sts := object.(*appsv1.StatefulSet)
+	existingSetupImage := ""
+	if setupContainer := setupContainer(&sts.Spec.Template.Spec); setupContainer != nil {
+		existingSetupImage = setupContainer.Image
+	}
+
+	if err := validateCommunityPlugins(builder.Instance); err != nil {
+		return err
+	}
+
+	if err := validateAdditionalVolumes(builder.Instance); err != nil {
+		return err
+	}
+
+	// the volume claim templates of an existing StatefulSet cannot change: when spec.persistence.enabled changed, the Pods keep
+	// the storage of the StatefulSet, as a volume claim template takes precedence over a Pod volume of the same name,
+	// and the NoWarnings condition reports it
+	ephemeralStorage := !builder.Instance.PersistenceEnabled()
+	if sts.ResourceVersion != "" {
+		if !hasVolumeClaimTemplate(sts, "persistence") {
+			ephemeralStorage = true
+		}
+		for _, volume := range builder.Instance.Spec.Persistence.AdditionalVolumes {
+			if !hasVolumeClaimTemplate(sts, volume.Name) {
+				return fmt.Errorf("spec.persistence.additionalVolumes cannot be added once the RabbitmqCluster is created: missing volume claim template %s", volume.Name)
+			}
+		}
+	}
 
 	//Replicas
 	sts.Spec.Replicas = builder.Instance.Spec.Replicas
@@ -157,13 +212,17 @@ func (builder *StatefulSetBuilder) Update(object runtime.Object) error {
 		"prometheus.io/scrape": "true",
 		"prometheus.io/port":   "15692",
 	}
+	if builder.Instance.RestrictedSecurityProfile() {
+		// Kubernetes versions before 1.19 ignore the seccompProfile field of the security context
+		defaultPodAnnotations[seccompPodAnnotation] = "runtime/default"
+	}
 	podAnnotations := metadata.ReconcileAnnotations(defaultPodAnnotations, metadata.ReconcileAndFilterAnnotations(sts.Spec.Template.Annotations, builder.Instance.Annotations))
 
 	//Labels
 	updatedLabels := metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
 	sts.Labels = updatedLabels
 
-	sts.Spec.Template = builder.podTemplateSpec(podAnnotations, updatedLabels)
+	sts.Spec.Template = builder.podTemplateSpec(podAnnotations, updatedLabels, ephemeralStorage)
 
 	if !sts.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().Equal(*sts.Spec.Template.Spec.Containers[0].Resources.Requests.Memory()) {
 		logger := ctrl.Log.WithName("statefulset").WithName("RabbitmqCluster")
@@ -176,12 +235,71 @@ func (builder *StatefulSetBuilder) Update(object runtime.Object) error {
 		}
 	}
 
+	if err := builder.keepSetupContainerImage(sts, existingSetupImage); err != nil {
+		return err
+	}
+
 	if err := controllerutil.SetControllerReference(builder.Instance, sts, builder.Scheme); err != nil {
 		return fmt.Errorf("failed setting controller reference: %v", err)
 	}
 	return nil
 }
 
+// setupContainerImage returns the image of the setup-container, spec.setupContainerImage or the operator image
+func (builder *StatefulSetBuilder) setupContainerImage() string {
+	if builder.Instance.Spec.SetupContainerImage != "" {
+		return builder.Instance.Spec.SetupContainerImage
+	}
+	return builder.SetupContainerImage
+}
+
+// keepSetupContainerImage keeps the setup-container image of an existing StatefulSet as long as nothing else in its Pod template changes.
+// The image is the operator image, so updating it on its own would restart every RabbitmqCluster on each operator upgrade;
+// the new image is rolled out with the next change of the Pod template instead.
+// An image set in spec.setupContainerImage or by the StatefulSet override is always used.
+func (builder *StatefulSetBuilder) keepSetupContainerImage(sts *appsv1.StatefulSet, existingImage string) error {
+	setupContainer := setupContainer(&sts.Spec.Template.Spec)
+	if setupContainer == nil || setupContainer.Image != builder.SetupContainerImage {
+		return nil
+	}
+
+	setupContainer.Image = ""
+	hash, err := podTemplateHash(sts.Spec.Template)
+	setupContainer.Image = builder.SetupContainerImage
+	if err != nil {
+		return err
+	}
+
+	if existingImage != "" && sts.Annotations[podTemplateHashAnnotation] == hash {
+		setupContainer.Image = existingImage
+	}
+	if sts.Annotations == nil {
+		sts.Annotations = map[string]string{}
+	}
+	sts.Annotations[podTemplateHashAnnotation] = hash
+	return nil
+}
+
+// podTemplateHash returns the hash of a Pod template, ignoring the restarts triggered by the operator
+func podTemplateHash(template corev1.PodTemplateSpec) (string, error) {
+	template = *template.DeepCopy()
+	delete(template.Annotations, RestartAtAnnotation)
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling statefulSet Pod template: %v", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+func setupContainer(podSpec *corev1.PodSpec) *corev1.Container {
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Name == "setup-container" {
+			return &podSpec.InitContainers[i]
+		}
+	}
+	return nil
+}
+
 func applyStsOverride(sts *appsv1.StatefulSet, stsOverride *rabbitmqv1beta1.StatefulSet) error {
 	if stsOverride.EmbeddedLabelsAnnotations != nil {
 		copyLabelsAnnotations(&sts.ObjectMeta, *stsOverride.EmbeddedLabelsAnnotations)
@@ -240,7 +358,7 @@ func patchPodSpec(podSpec, podSpecOverride *corev1.PodSpec) (corev1.PodSpec, err
 	return patchedPodSpec, nil
 }
 
-func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[string]string) corev1.PodTemplateSpec {
+func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[string]string, ephemeralStorage bool) corev1.PodTemplateSpec {
 	//Init Container resources
 	cpuRequest := k8sresource.MustParse(initContainerCPU)
 	memoryRequest := k8sresource.MustParse(initContainerMemory)
@@ -255,7 +373,9 @@ func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[strin
 	rabbitmqGID := int64(999)
 	rabbitmqUID := int64(999)
 
-	terminationGracePeriod := defaultGracePeriodTimeoutSeconds
+	terminationGracePeriod := builder.Instance.TerminationGracePeriodSeconds()
+
+	readinessProbe, livenessProbe, startupProbe := builder.probes()
 
 	volumes := []corev1.Volume{
 		{
@@ -352,28 +472,10 @@ func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[strin
 		},
 	}
 
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_mqtt") {
-		ports = append(ports, corev1.ContainerPort{
-			Name:          "mqtt",
-			ContainerPort: 1883,
-		})
-	}
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_web_mqtt") {
+	for _, port := range PluginPorts(builder.Instance, false) {
 		ports = append(ports, corev1.ContainerPort{
-			Name:          "web-mqtt",
-			ContainerPort: 15675,
-		})
-	}
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_stomp") {
-		ports = append(ports, corev1.ContainerPort{
-			Name:          "stomp",
-			ContainerPort: 61613,
-		})
-	}
-	if builder.Instance.AdditionalPluginEnabled("rabbitmq_web_stomp") {
-		ports = append(ports, corev1.ContainerPort{
-			Name:          "web-stomp",
-			ContainerPort: 15674,
+			Name:          port.Name,
+			ContainerPort: port.Port,
 		})
 	}
 
@@ -407,6 +509,12 @@ func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[strin
 			Name:          "amqps",
 			ContainerPort: 5671,
 		})
+		for _, port := range PluginPorts(builder.Instance, true) {
+			ports = append(ports, corev1.ContainerPort{
+				Name:          port.Name,
+				ContainerPort: port.Port,
+			})
+		}
 
 		// add tls volume
 		filePermissions := int32(400)
@@ -473,7 +581,20 @@ func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[strin
 		}
 	}
 
-	return corev1.PodTemplateSpec{
+	if ephemeralStorage {
+		volumes = append(volumes, corev1.Volume{
+			Name: "persistence",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: builder.Instance.Spec.Persistence.EmptyDirMedium,
+				},
+			},
+		})
+	}
+
+	affinity, topologySpreadConstraints := builder.placement()
+
+	podTemplate := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations: annotations,
 			Labels:      labels,
@@ -488,12 +609,13 @@ func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[strin
 			TerminationGracePeriodSeconds: &terminationGracePeriod,
 			ServiceAccountName:            builder.Instance.ChildResourceName(serviceAccountName),
 			AutomountServiceAccountToken:  &automountServiceAccountToken,
-			Affinity:                      builder.Instance.Spec.Affinity,
+			Affinity:                      affinity,
+			TopologySpreadConstraints:     topologySpreadConstraints,
 			Tolerations:                   builder.Instance.Spec.Tolerations,
 			InitContainers: []corev1.Container{
 				{
 					Name:  "setup-container",
-					Image: builder.Instance.Spec.Image,
+					Image: builder.setupContainerImage(),
 					SecurityContext: &corev1.SecurityContext{
 						RunAsUser: pointer.Int64Ptr(0),
 						Capabilities: &corev1.Capabilities{
@@ -501,21 +623,9 @@ func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[strin
 							Add:  []corev1.Capability{"CHOWN", "FOWNER"},
 						},
 					},
-					Command: []string{
-						"sh", "-c", "cp /tmp/rabbitmq/rabbitmq.conf /etc/rabbitmq/rabbitmq.conf " +
-							"&& chown 999:999 /etc/rabbitmq/rabbitmq.conf " +
-							"&& echo '' >> /etc/rabbitmq/rabbitmq.conf ; " +
-							"cp /tmp/rabbitmq/advanced.config /etc/rabbitmq/advanced.config " +
-							"&& chown 999:999 /etc/rabbitmq/advanced.config ; " +
-							"cp /tmp/rabbitmq/rabbitmq-env.conf /etc/rabbitmq/rabbitmq-env.conf " +
-							"&& chown 999:999 /etc/rabbitmq/rabbitmq-env.conf ; " +
-							"cp /tmp/erlang-cookie-secret/.erlang.cookie /var/lib/rabbitmq/.erlang.cookie " +
-							"&& chown 999:999 /var/lib/rabbitmq/.erlang.cookie " +
-							"&& chmod 600 /var/lib/rabbitmq/.erlang.cookie ; " +
-							"cp /tmp/rabbitmq-plugins/enabled_plugins /etc/rabbitmq/enabled_plugins " +
-							"&& chown 999:999 /etc/rabbitmq/enabled_plugins ; " +
-							"chgrp 999 /var/lib/rabbitmq/mnesia/",
-					},
+					Command:                  []string{setupContainerBinary},
+					Args:                     builder.setupContainerArgs(),
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 					Resources: corev1.ResourceRequirements{
 						Limits: map[corev1.ResourceName]k8sresource.Quantity{
 							"cpu":    cpuRequest,
@@ -604,35 +714,238 @@ func (builder *StatefulSetBuilder) podTemplateSpec(annotations, labels map[strin
 							Value: ".$(K8S_SERVICE_NAME).$(MY_POD_NAMESPACE)",
 						},
 					},
-					Ports:        ports,
-					VolumeMounts: rabbitmqContainerVolumeMounts,
-					ReadinessProbe: &corev1.Probe{
-						Handler: corev1.Handler{
-							Exec: &corev1.ExecAction{
-								Command: []string{"/bin/sh", "-c", "rabbitmq-diagnostics check_port_connectivity"},
-							},
-						},
-						InitialDelaySeconds: 10,
-						TimeoutSeconds:      5,
-						PeriodSeconds:       30,
-						SuccessThreshold:    1,
-						FailureThreshold:    3,
-					},
-					Lifecycle: &corev1.Lifecycle{
-						PreStop: &corev1.Handler{
-							Exec: &corev1.ExecAction{
-								Command: []string{"/bin/bash", "-c",
-									fmt.Sprintf("if [ ! -z \"$(cat /etc/pod-info/%s)\" ]; then exit 0; fi;", DeletionMarker) +
-										fmt.Sprintf(" rabbitmq-upgrade await_online_quorum_plus_one -t %d;"+
-											" rabbitmq-upgrade await_online_synchronized_mirror -t %d", defaultGracePeriodTimeoutSeconds, defaultGracePeriodTimeoutSeconds),
-								},
-							},
-						},
+					Ports:          ports,
+					VolumeMounts:   rabbitmqContainerVolumeMounts,
+					ReadinessProbe: readinessProbe,
+					LivenessProbe:  livenessProbe,
+					StartupProbe:   startupProbe,
+					Lifecycle:      builder.lifecycle(),
+				},
+			},
+		},
+	}
+	builder.addCommunityPlugins(&podTemplate.Spec)
+	builder.addAdditionalVolumes(&podTemplate.Spec)
+	builder.applySecurityProfile(&podTemplate.Spec)
+
+	return podTemplate
+}
+
+// setupContainerArgs returns the arguments of the setup-container binary, which copies the configuration files and the Erlang cookie
+// into the volumes of the RabbitMQ container.
+// Under the restricted security profile the setup-container runs as the user of the RabbitMQ container, which then owns the copied files.
+func (builder *StatefulSetBuilder) setupContainerArgs() []string {
+	var args []string
+	if !builder.Instance.RestrictedSecurityProfile() {
+		args = append(args, "--uid=999", "--gid=999")
+	}
+	if domain := builder.streamAdvertisedDomain(); domain != "" {
+		args = append(args, "--stream-advertised-domain="+domain)
+		if builder.Instance.TLSEnabled() {
+			args = append(args, "--stream-advertised-tls")
+		}
+	}
+	return args
+}
+
+// applySecurityProfile replaces the fixed user, group and privileges of the Pod with the restricted settings when spec.securityProfile is Restricted.
+// The user and group are left to the namespace, e.g. the UID range of the OpenShift project; the fsGroup stays 999 so that RabbitMQ can write to its volumes.
+func (builder *StatefulSetBuilder) applySecurityProfile(podSpec *corev1.PodSpec) {
+	if !builder.Instance.RestrictedSecurityProfile() {
+		return
+	}
+
+	podSpec.SecurityContext = &corev1.PodSecurityContext{
+		FSGroup:      pointer.Int64Ptr(999),
+		RunAsNonRoot: pointer.BoolPtr(true),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			containers[i].SecurityContext = &corev1.SecurityContext{
+				RunAsNonRoot:             pointer.BoolPtr(true),
+				AllowPrivilegeEscalation: pointer.BoolPtr(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			}
+		}
+	}
+}
+
+// probes returns the readiness, liveness and startup probes of the RabbitMQ container.
+// The readiness and startup probes are always set; the liveness probe only when configured in spec.probes,
+// and it only starts once the startup probe succeeds, so that it does not restart nodes while they boot.
+func (builder *StatefulSetBuilder) probes() (readiness, liveness, startup *corev1.Probe) {
+	var spec rabbitmqv1beta1.RabbitmqClusterProbesSpec
+	if builder.Instance.Spec.Probes != nil {
+		spec = *builder.Instance.Spec.Probes
+	}
+
+	readiness = probe(spec.Readiness, "check_port_connectivity", corev1.Probe{
+		InitialDelaySeconds: 10,
+		TimeoutSeconds:      5,
+		PeriodSeconds:       30,
+		SuccessThreshold:    1,
+		FailureThreshold:    3,
+	})
+	if spec.Liveness != nil {
+		liveness = probe(spec.Liveness, "ping", corev1.Probe{
+			InitialDelaySeconds: 60,
+			TimeoutSeconds:      20,
+			PeriodSeconds:       60,
+			SuccessThreshold:    1,
+			FailureThreshold:    3,
+		})
+	}
+	// allows nodes up to 30 minutes to boot
+	startup = probe(spec.Startup, "check_running", corev1.Probe{
+		InitialDelaySeconds: 10,
+		TimeoutSeconds:      10,
+		PeriodSeconds:       10,
+		SuccessThreshold:    1,
+		FailureThreshold:    180,
+	})
+	return readiness, liveness, startup
+}
+
+// probe returns a probe running the rabbitmq-diagnostics check of spec, with the defaults of the probe type for unset fields
+func probe(spec *rabbitmqv1beta1.RabbitmqClusterProbeSpec, defaultCheck string, defaults corev1.Probe) *corev1.Probe {
+	check := defaultCheck
+	result := defaults
+	if spec != nil {
+		if spec.Check != "" {
+			check = spec.Check
+		}
+		if spec.InitialDelaySeconds != nil {
+			result.InitialDelaySeconds = *spec.InitialDelaySeconds
+		}
+		if spec.TimeoutSeconds != nil {
+			result.TimeoutSeconds = *spec.TimeoutSeconds
+		}
+		if spec.PeriodSeconds != nil {
+			result.PeriodSeconds = *spec.PeriodSeconds
+		}
+		if spec.SuccessThreshold != nil {
+			result.SuccessThreshold = *spec.SuccessThreshold
+		}
+		if spec.FailureThreshold != nil {
+			result.FailureThreshold = *spec.FailureThreshold
+		}
+	}
+	result.Handler = corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"/bin/sh", "-c", "rabbitmq-diagnostics " + check},
+		},
+	}
+	return &result
+}
+
+// lifecycle returns the pre-stop hook of the RabbitMQ container, or nil when spec.preStop disables every step.
+// The hook exits straight away when the operator has marked the Pod for deletion with DeletionMarker.
+func (builder *StatefulSetBuilder) lifecycle() *corev1.Lifecycle {
+	timeout := builder.Instance.PreStopTimeoutSeconds()
+	var steps []string
+	if builder.Instance.PreStopDrain() {
+		steps = append(steps, fmt.Sprintf("rabbitmq-upgrade drain -t %d", timeout))
+	}
+	if builder.Instance.PreStopAwaitQuorumPlusOne() {
+		steps = append(steps, fmt.Sprintf("rabbitmq-upgrade await_online_quorum_plus_one -t %d", timeout))
+	}
+	if builder.Instance.PreStopAwaitSynchronizedMirror() {
+		steps = append(steps, fmt.Sprintf("rabbitmq-upgrade await_online_synchronized_mirror -t %d", timeout))
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+
+	return &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/bash", "-c",
+					fmt.Sprintf("if [ ! -z \"$(cat /etc/pod-info/%s)\" ]; then exit 0; fi; ", DeletionMarker) + strings.Join(steps, "; "),
+				},
+			},
+		},
+	}
+}
+
+// streamAdvertisedDomain returns the domain the stream plugin advertises each node under, after the hostname of the node,
+// or an empty string when the plugin is disabled or its advertised host is configured in spec.rabbitmq.additionalConfig.
+// stream clients connect to the host and port a node advertises, so every node must advertise its own address
+func (builder *StatefulSetBuilder) streamAdvertisedDomain() string {
+	if !builder.Instance.StreamEnabled() || strings.Contains(builder.Instance.Spec.Rabbitmq.AdditionalConfig, "stream.advertised_host") {
+		return ""
+	}
+
+	// the hostname of a Pod is its name, which is also the name of its per-pod Service
+	domain := fmt.Sprintf("%s.%s.svc", builder.Instance.ChildResourceName(headlessServiceName), builder.Instance.Namespace)
+	if builder.Instance.PerPodServiceEnabled() {
+		domain = fmt.Sprintf("%s.svc", builder.Instance.Namespace)
+		if builder.Instance.Spec.PerPodService.ExternalDomain != "" {
+			domain = builder.Instance.Spec.PerPodService.ExternalDomain
+		}
+	}
+	return domain
+}
+
+// placement returns the affinity and topology spread constraints of the RabbitMQ Pods
+// a user-provided affinity always wins over spec.placement, which is opt-in so that existing Pod templates do not change
+func (builder *StatefulSetBuilder) placement() (*corev1.Affinity, []corev1.TopologySpreadConstraint) {
+	if builder.Instance.Spec.Affinity != nil {
+		return builder.Instance.Spec.Affinity, nil
+	}
+	if builder.Instance.Spec.Placement == nil {
+		return nil, nil
+	}
+	placement := *builder.Instance.Spec.Placement
+
+	weight := int32(100)
+	if placement.AntiAffinityWeight != 0 {
+		weight = placement.AntiAffinityWeight
+	}
+	zoneTopologyKey := "topology.kubernetes.io/zone"
+	if placement.ZoneTopologyKey != "" {
+		zoneTopologyKey = placement.ZoneTopologyKey
+	}
+	maxSkew := int32(1)
+	if placement.MaxSkew != 0 {
+		maxSkew = placement.MaxSkew
+	}
+	whenUnsatisfiable := corev1.ScheduleAnyway
+	if placement.WhenUnsatisfiable != "" {
+		whenUnsatisfiable = placement.WhenUnsatisfiable
+	}
+
+	selector := &metav1.LabelSelector{
+		MatchLabels: metadata.LabelSelector(builder.Instance.Name),
+	}
+
+	affinity := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: weight,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: selector,
+						TopologyKey:   "kubernetes.io/hostname",
 					},
 				},
 			},
 		},
 	}
+	topologySpreadConstraints := []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           maxSkew,
+			TopologyKey:       zoneTopologyKey,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector:     selector,
+		},
+	}
+
+	return affinity, topologySpreadConstraints
 }
 
 func copyLabelsAnnotations(base *metav1.ObjectMeta, override rabbitmqv1beta1.EmbeddedLabelsAnnotations) {
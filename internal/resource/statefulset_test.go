@@ -95,6 +95,25 @@ var _ = Describe("StatefulSet", func() {
 			q, _ := k8sresource.ParseQuantity("21Gi")
 			Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"]).To(Equal(q))
 		})
+		Context("persistence disabled", func() {
+			It("does not create a PersistentVolumeClaim template when persistence is disabled", func() {
+				builder.Instance.Spec.Persistence.Enabled = pointer.BoolPtr(false)
+
+				obj, err := stsBuilder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(obj.(*appsv1.StatefulSet).Spec.VolumeClaimTemplates).To(BeEmpty())
+			})
+
+			It("does not create a PersistentVolumeClaim template when the storage size is 0", func() {
+				storage := k8sresource.MustParse("0")
+				builder.Instance.Spec.Persistence.Storage = &storage
+
+				obj, err := stsBuilder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(obj.(*appsv1.StatefulSet).Spec.VolumeClaimTemplates).To(BeEmpty())
+			})
+		})
+
 		Context("PVC template", func() {
 			It("creates the required PersistentVolumeClaim", func() {
 				q, _ := k8sresource.ParseQuantity("10Gi")
@@ -277,8 +296,9 @@ var _ = Describe("StatefulSet", func() {
 			Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
 
 			builder = &resource.RabbitmqResourceBuilder{
-				Instance: &instance,
-				Scheme:   scheme,
+				Instance:            &instance,
+				Scheme:              scheme,
+				SetupContainerImage: "operator-image",
 			}
 
 			stsBuilder = builder.StatefulSet()
@@ -315,6 +335,123 @@ var _ = Describe("StatefulSet", func() {
 			Expect(statefulSet.Spec.Template.Spec.Affinity).To(Equal(affinity))
 		})
 
+		Context("persistence disabled", func() {
+			BeforeEach(func() {
+				stsBuilder.Instance.Spec.Persistence.Enabled = pointer.BoolPtr(false)
+			})
+
+			It("stores RabbitMQ data in an emptyDir volume", func() {
+				stsBuilder.Instance.Spec.Persistence.EmptyDirMedium = corev1.StorageMediumMemory
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "persistence",
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+					},
+				}))
+				Expect(extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq").VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "persistence",
+					MountPath: "/var/lib/rabbitmq/mnesia/",
+				}))
+				Expect(extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container").VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "persistence",
+					MountPath: "/var/lib/rabbitmq/mnesia/",
+				}))
+			})
+
+			It("keeps the PersistentVolumeClaim template of the existing StatefulSet", func() {
+				statefulSet.ResourceVersion = "1"
+				statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "persistence"}}}
+
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+				Expect(statefulSet.Spec.VolumeClaimTemplates).To(HaveLen(1))
+				Expect(statefulSet.Spec.VolumeClaimTemplates[0].Name).To(Equal("persistence"))
+			})
+		})
+
+		When("persistence is enabled after the StatefulSet was created without a PersistentVolumeClaim template", func() {
+			It("keeps storing RabbitMQ data in an emptyDir volume", func() {
+				statefulSet.ResourceVersion = "1"
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.VolumeClaimTemplates).To(BeEmpty())
+				Expect(statefulSet.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "persistence",
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				}))
+			})
+		})
+
+		Context("placement", func() {
+			BeforeEach(func() {
+				stsBuilder.Instance.Spec.Affinity = nil
+			})
+
+			It("does not set any placement by default", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.Template.Spec.Affinity).To(BeNil())
+				Expect(statefulSet.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+			})
+
+			It("prefers to schedule Pods on different nodes and spreads them across zones when spec.placement is set", func() {
+				stsBuilder.Instance.Spec.Placement = &rabbitmqv1beta1.RabbitmqClusterPlacementSpec{}
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				selector := &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app.kubernetes.io/name": instance.Name},
+				}
+				Expect(statefulSet.Spec.Template.Spec.Affinity).To(Equal(&corev1.Affinity{
+					PodAntiAffinity: &corev1.PodAntiAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+							{
+								Weight: 100,
+								PodAffinityTerm: corev1.PodAffinityTerm{
+									LabelSelector: selector,
+									TopologyKey:   "kubernetes.io/hostname",
+								},
+							},
+						},
+					},
+				}))
+				Expect(statefulSet.Spec.Template.Spec.TopologySpreadConstraints).To(ConsistOf(corev1.TopologySpreadConstraint{
+					MaxSkew:           1,
+					TopologyKey:       "topology.kubernetes.io/zone",
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+					LabelSelector:     selector,
+				}))
+			})
+
+			It("uses the settings from spec.placement", func() {
+				stsBuilder.Instance.Spec.Placement = &rabbitmqv1beta1.RabbitmqClusterPlacementSpec{
+					AntiAffinityWeight: 50,
+					ZoneTopologyKey:    "failure-domain.beta.kubernetes.io/zone",
+					MaxSkew:            2,
+					WhenUnsatisfiable:  corev1.DoNotSchedule,
+				}
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				podSpec := statefulSet.Spec.Template.Spec
+				Expect(podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Weight).To(Equal(int32(50)))
+				Expect(podSpec.TopologySpreadConstraints).To(HaveLen(1))
+				Expect(podSpec.TopologySpreadConstraints[0].TopologyKey).To(Equal("failure-domain.beta.kubernetes.io/zone"))
+				Expect(podSpec.TopologySpreadConstraints[0].MaxSkew).To(Equal(int32(2)))
+				Expect(podSpec.TopologySpreadConstraints[0].WhenUnsatisfiable).To(Equal(corev1.DoNotSchedule))
+			})
+
+			It("does not apply spec.placement when the user sets an affinity", func() {
+				stsBuilder.Instance.Spec.Placement = &rabbitmqv1beta1.RabbitmqClusterPlacementSpec{}
+				stsBuilder.Instance.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.Template.Spec.Affinity.PodAntiAffinity).To(BeNil())
+				Expect(statefulSet.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+			})
+		})
+
 		It("sets the owner reference", func() {
 			stsBuilder := builder.StatefulSet()
 			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
@@ -520,6 +657,8 @@ var _ = Describe("StatefulSet", func() {
 						"app.k8s.io/something":             "something-amazing",
 					}
 
+					Expect(statefulSet.Annotations).To(HaveKey("rabbitmq.com/podTemplateHash"))
+					delete(statefulSet.Annotations, "rabbitmq.com/podTemplateHash")
 					Expect(statefulSet.Annotations).To(Equal(expectedAnnotations))
 				})
 
@@ -734,8 +873,66 @@ var _ = Describe("StatefulSet", func() {
 			Entry("MQTT-over-WebSockets", "rabbitmq_web_mqtt", "web-mqtt", 15675),
 			Entry("STOMP", "rabbitmq_stomp", "stomp", 61613),
 			Entry("STOMP-over-WebSockets", "rabbitmq_web_stomp", "web-stomp", 15674),
+			Entry("Streams", "rabbitmq_stream", "stream", 5552),
 		)
 
+		It("exposes the stream TLS port when TLS is enabled", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_stream"}
+			instance.Spec.TLS.SecretName = "tls-secret"
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+			container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+			Expect(container.Ports).To(ContainElement(corev1.ContainerPort{
+				Name:          "streams",
+				ContainerPort: 5551,
+			}))
+		})
+
+		Context("stream advertised host", func() {
+			setupArgs := func() []string {
+				initContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+				return initContainer.Args
+			}
+
+			BeforeEach(func() {
+				instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_stream"}
+			})
+
+			It("advertises the address of the Pod in the headless Service", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(setupArgs()).To(Equal([]string{"--uid=999", "--gid=999", "--stream-advertised-domain=foo-rabbitmq-headless.foo-namespace.svc"}))
+			})
+
+			It("advertises the per-pod Service of the Pod", func() {
+				instance.Spec.PerPodService = &rabbitmqv1beta1.RabbitmqClusterPerPodServiceSpec{}
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(setupArgs()).To(ContainElement("--stream-advertised-domain=foo-namespace.svc"))
+			})
+
+			It("advertises the external domain of the per-pod Services", func() {
+				instance.Spec.PerPodService = &rabbitmqv1beta1.RabbitmqClusterPerPodServiceSpec{ExternalDomain: "rabbitmq.example.com"}
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(setupArgs()).To(ContainElement("--stream-advertised-domain=rabbitmq.example.com"))
+			})
+
+			It("advertises the stream TLS port when TLS is enabled", func() {
+				instance.Spec.TLS.SecretName = "tls-secret"
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(setupArgs()).To(ContainElement("--stream-advertised-tls"))
+			})
+
+			It("leaves the advertised host to spec.rabbitmq.additionalConfig when it is set there", func() {
+				instance.Spec.Rabbitmq.AdditionalConfig = "stream.advertised_host = rabbit.example.com"
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(setupArgs()).To(Equal([]string{"--uid=999", "--gid=999"}))
+			})
+		})
+
 		It("uses required Environment Variables", func() {
 			stsBuilder := builder.StatefulSet()
 			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
@@ -928,6 +1125,129 @@ var _ = Describe("StatefulSet", func() {
 			Expect(statefulSet.Spec.Template.Spec.SecurityContext).To(Equal(expectedPodSecurityContext))
 		})
 
+		Context("restricted security profile", func() {
+			BeforeEach(func() {
+				instance.Spec.SecurityProfile = rabbitmqv1beta1.RestrictedSecurityProfile
+				instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{{
+					Name: "rabbitmq_message_timestamp",
+					Source: &rabbitmqv1beta1.CommunityPluginSource{
+						Image: "example.com/rabbitmq-plugins:3.8",
+					},
+				}}
+			})
+
+			It("leaves the user and group to the namespace and uses the runtime/default seccomp profile", func() {
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				runAsNonRoot := true
+				fsGroup := int64(999)
+				Expect(statefulSet.Spec.Template.Spec.SecurityContext).To(Equal(&corev1.PodSecurityContext{
+					FSGroup:      &fsGroup,
+					RunAsNonRoot: &runAsNonRoot,
+					SeccompProfile: &corev1.SeccompProfile{
+						Type: corev1.SeccompProfileTypeRuntimeDefault,
+					},
+				}))
+				Expect(statefulSet.Spec.Template.Annotations).To(HaveKeyWithValue("seccomp.security.alpha.kubernetes.io/pod", "runtime/default"))
+			})
+
+			It("runs every container unprivileged", func() {
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				runAsNonRoot, allowPrivilegeEscalation := true, false
+				expectedSecurityContext := &corev1.SecurityContext{
+					RunAsNonRoot:             &runAsNonRoot,
+					AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+					Capabilities: &corev1.Capabilities{
+						Drop: []corev1.Capability{"ALL"},
+					},
+				}
+				containers := append(statefulSet.Spec.Template.Spec.InitContainers, statefulSet.Spec.Template.Spec.Containers...)
+				Expect(containers).To(HaveLen(3))
+				for _, container := range containers {
+					Expect(container.SecurityContext).To(Equal(expectedSecurityContext), container.Name)
+				}
+			})
+
+			It("copies the configuration files without changing their owner", func() {
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				initContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+				Expect(initContainer.Command).To(Equal([]string{"/setup-container"}))
+				Expect(initContainer.Args).To(BeEmpty())
+			})
+		})
+
+		Context("setup-container image", func() {
+			setupImage := func() string {
+				return extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container").Image
+			}
+
+			BeforeEach(func() {
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				builder.SetupContainerImage = "new-operator-image"
+			})
+
+			It("uses the operator image for a new StatefulSet", func() {
+				Expect(setupImage()).To(Equal("operator-image"))
+				Expect(statefulSet.Annotations).To(HaveKey("rabbitmq.com/podTemplateHash"))
+			})
+
+			It("keeps the existing image when nothing else in the Pod template changes", func() {
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				Expect(setupImage()).To(Equal("operator-image"))
+			})
+
+			It("keeps the existing image when the Pods are restarted", func() {
+				statefulSet.Spec.Template.Annotations["rabbitmq.com/restartAt"] = "2020-01-01T00:00:00Z"
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				Expect(setupImage()).To(Equal("operator-image"))
+			})
+
+			It("rolls out the new image with the next Pod template change", func() {
+				instance.Spec.Image = "rabbitmq:a-new-version"
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				Expect(setupImage()).To(Equal("new-operator-image"))
+
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				Expect(setupImage()).To(Equal("new-operator-image"))
+			})
+
+			It("uses the new image when the StatefulSet has no Pod template hash", func() {
+				delete(statefulSet.Annotations, "rabbitmq.com/podTemplateHash")
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				Expect(setupImage()).To(Equal("new-operator-image"))
+			})
+
+			It("always uses the image set in spec.setupContainerImage", func() {
+				instance.Spec.SetupContainerImage = "my-registry/operator-image"
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				Expect(setupImage()).To(Equal("my-registry/operator-image"))
+			})
+
+			It("always uses the image set in the StatefulSet override", func() {
+				instance.Spec.Override.StatefulSet = &rabbitmqv1beta1.StatefulSet{
+					Spec: &rabbitmqv1beta1.StatefulSetSpec{
+						Template: &rabbitmqv1beta1.PodTemplateSpec{
+							Spec: &corev1.PodSpec{
+								InitContainers: []corev1.Container{
+									{
+										Name:  "setup-container",
+										Image: "my-setup-image",
+									},
+								},
+							},
+						},
+					},
+				}
+				Expect(builder.StatefulSet().Update(statefulSet)).To(Succeed())
+				Expect(setupImage()).To(Equal("my-setup-image"))
+			})
+		})
+
 		It("defines a Readiness Probe", func() {
 			stsBuilder := builder.StatefulSet()
 			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
@@ -937,6 +1257,65 @@ var _ = Describe("StatefulSet", func() {
 			Expect(actualProbeCommand).To(Equal([]string{"/bin/sh", "-c", "rabbitmq-diagnostics check_port_connectivity"}))
 		})
 
+		It("defines a Startup Probe allowing nodes 30 minutes to boot, and no Liveness Probe, by default", func() {
+			stsBuilder := builder.StatefulSet()
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+			container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+			Expect(container.ReadinessProbe.PeriodSeconds).To(Equal(int32(30)))
+			Expect(container.LivenessProbe).To(BeNil())
+			Expect(container.StartupProbe.Handler.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "rabbitmq-diagnostics check_running"}))
+			Expect(container.StartupProbe.PeriodSeconds * container.StartupProbe.FailureThreshold).To(Equal(int32(1800)))
+		})
+
+		Context("probes", func() {
+			It("uses the default check of each probe type", func() {
+				instance.Spec.Probes = &rabbitmqv1beta1.RabbitmqClusterProbesSpec{
+					Liveness: &rabbitmqv1beta1.RabbitmqClusterProbeSpec{},
+				}
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+				Expect(container.LivenessProbe.Handler.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "rabbitmq-diagnostics ping"}))
+				Expect(container.StartupProbe.Handler.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "rabbitmq-diagnostics check_running"}))
+				Expect(container.StartupProbe.FailureThreshold).To(Equal(int32(180)))
+			})
+
+			It("uses the check and timings of the RabbitmqCluster", func() {
+				period := int32(5)
+				failureThreshold := int32(720)
+				instance.Spec.Probes = &rabbitmqv1beta1.RabbitmqClusterProbesSpec{
+					Readiness: &rabbitmqv1beta1.RabbitmqClusterProbeSpec{
+						Check:         "check_running",
+						PeriodSeconds: &period,
+					},
+					Startup: &rabbitmqv1beta1.RabbitmqClusterProbeSpec{
+						FailureThreshold: &failureThreshold,
+					},
+				}
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+				Expect(container.ReadinessProbe).To(Equal(&corev1.Probe{
+					Handler: corev1.Handler{
+						Exec: &corev1.ExecAction{
+							Command: []string{"/bin/sh", "-c", "rabbitmq-diagnostics check_running"},
+						},
+					},
+					InitialDelaySeconds: 10,
+					TimeoutSeconds:      5,
+					PeriodSeconds:       5,
+					SuccessThreshold:    1,
+					FailureThreshold:    3,
+				}))
+				Expect(container.StartupProbe.FailureThreshold).To(Equal(int32(720)))
+				Expect(container.StartupProbe.PeriodSeconds).To(Equal(int32(10)))
+				Expect(container.LivenessProbe).To(BeNil())
+			})
+		})
+
 		It("templates the correct InitContainer", func() {
 			stsBuilder := builder.StatefulSet()
 			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
@@ -946,28 +1325,16 @@ var _ = Describe("StatefulSet", func() {
 
 			initContainer := extractContainer(initContainers, "setup-container")
 			Expect(initContainer).To(MatchFields(IgnoreExtras, Fields{
-				"Image": Equal("rabbitmq-image-from-cr"),
+				"Image": Equal("operator-image"),
 				"SecurityContext": PointTo(MatchFields(IgnoreExtras, Fields{
 					"Capabilities": PointTo(MatchAllFields(Fields{
 						"Drop": ConsistOf([]corev1.Capability{"ALL"}),
 						"Add":  ConsistOf([]corev1.Capability{"CHOWN", "FOWNER"}),
 					})),
 				})),
-				"Command": ConsistOf(
-					"sh", "-c", "cp /tmp/rabbitmq/rabbitmq.conf /etc/rabbitmq/rabbitmq.conf "+
-						"&& chown 999:999 /etc/rabbitmq/rabbitmq.conf "+
-						"&& echo '' >> /etc/rabbitmq/rabbitmq.conf ; "+
-						"cp /tmp/rabbitmq/advanced.config /etc/rabbitmq/advanced.config "+
-						"&& chown 999:999 /etc/rabbitmq/advanced.config ; "+
-						"cp /tmp/rabbitmq/rabbitmq-env.conf /etc/rabbitmq/rabbitmq-env.conf "+
-						"&& chown 999:999 /etc/rabbitmq/rabbitmq-env.conf ; "+
-						"cp /tmp/erlang-cookie-secret/.erlang.cookie /var/lib/rabbitmq/.erlang.cookie "+
-						"&& chown 999:999 /var/lib/rabbitmq/.erlang.cookie "+
-						"&& chmod 600 /var/lib/rabbitmq/.erlang.cookie ; "+
-						"cp /tmp/rabbitmq-plugins/enabled_plugins /etc/rabbitmq/enabled_plugins "+
-						"&& chown 999:999 /etc/rabbitmq/enabled_plugins ; "+
-						"chgrp 999 /var/lib/rabbitmq/mnesia/",
-				),
+				"Command":                  ConsistOf("/setup-container"),
+				"Args":                     ConsistOf("--uid=999", "--gid=999"),
+				"TerminationMessagePolicy": Equal(corev1.TerminationMessageFallbackToLogsOnError),
 				"VolumeMounts": ConsistOf(
 					corev1.VolumeMount{
 						Name:      "server-conf",
@@ -1015,6 +1382,70 @@ var _ = Describe("StatefulSet", func() {
 			Expect(statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal(expectedPreStopCommand))
 		})
 
+		It("uses the terminationGracePeriodSeconds of the RabbitmqCluster", func() {
+			gracePeriod := int64(3600)
+			instance.Spec.TerminationGracePeriodSeconds = &gracePeriod
+			stsBuilder := builder.StatefulSet()
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+			Expect(*statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(3600)))
+			expectedPreStopCommand := []string{"/bin/bash", "-c", "if [ ! -z \"$(cat /etc/pod-info/skipPreStopChecks)\" ]; then exit 0; fi; rabbitmq-upgrade await_online_quorum_plus_one -t 1800; rabbitmq-upgrade await_online_synchronized_mirror -t 1800"}
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal(expectedPreStopCommand))
+		})
+
+		Context("preStop policy", func() {
+			It("drains the node first and uses the timeout of the policy", func() {
+				timeout := int64(600)
+				instance.Spec.PreStop = &rabbitmqv1beta1.RabbitmqClusterPreStopSpec{
+					Drain:          true,
+					TimeoutSeconds: &timeout,
+				}
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				expectedPreStopCommand := []string{"/bin/bash", "-c", "if [ ! -z \"$(cat /etc/pod-info/skipPreStopChecks)\" ]; then exit 0; fi; rabbitmq-upgrade drain -t 600; rabbitmq-upgrade await_online_quorum_plus_one -t 600; rabbitmq-upgrade await_online_synchronized_mirror -t 600"}
+				Expect(statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal(expectedPreStopCommand))
+				Expect(*statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(604800)))
+			})
+
+			It("caps the timeout so that all steps fit within the terminationGracePeriodSeconds", func() {
+				gracePeriod := int64(900)
+				timeout := int64(600)
+				instance.Spec.TerminationGracePeriodSeconds = &gracePeriod
+				instance.Spec.PreStop = &rabbitmqv1beta1.RabbitmqClusterPreStopSpec{
+					Drain:          true,
+					TimeoutSeconds: &timeout,
+				}
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				expectedPreStopCommand := []string{"/bin/bash", "-c", "if [ ! -z \"$(cat /etc/pod-info/skipPreStopChecks)\" ]; then exit 0; fi; rabbitmq-upgrade drain -t 300; rabbitmq-upgrade await_online_quorum_plus_one -t 300; rabbitmq-upgrade await_online_synchronized_mirror -t 300"}
+				Expect(statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal(expectedPreStopCommand))
+			})
+
+			It("skips the disabled checks", func() {
+				instance.Spec.PreStop = &rabbitmqv1beta1.RabbitmqClusterPreStopSpec{
+					AwaitSynchronizedMirror: new(bool),
+				}
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				expectedPreStopCommand := []string{"/bin/bash", "-c", "if [ ! -z \"$(cat /etc/pod-info/skipPreStopChecks)\" ]; then exit 0; fi; rabbitmq-upgrade await_online_quorum_plus_one -t 604800"}
+				Expect(statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal(expectedPreStopCommand))
+			})
+
+			It("removes the preStop hook when every step is disabled", func() {
+				instance.Spec.PreStop = &rabbitmqv1beta1.RabbitmqClusterPreStopSpec{
+					AwaitQuorumPlusOne:      new(bool),
+					AwaitSynchronizedMirror: new(bool),
+				}
+				stsBuilder := builder.StatefulSet()
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+			})
+		})
+
 		Context("resources requirements", func() {
 
 			It("sets StatefulSet resource requirements", func() {
@@ -1121,6 +1552,8 @@ var _ = Describe("StatefulSet", func() {
 					"app.kubernetes.io/part-of":   "rabbitmq",
 				}))
 
+				Expect(statefulSet.ObjectMeta.Annotations).To(HaveKey("rabbitmq.com/podTemplateHash"))
+				delete(statefulSet.ObjectMeta.Annotations, "rabbitmq.com/podTemplateHash")
 				Expect(statefulSet.ObjectMeta.Annotations).To(Equal(map[string]string{
 					"new-key": "new-value",
 					"key1":    "new-value",
@@ -1146,6 +1579,8 @@ var _ = Describe("StatefulSet", func() {
 				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
 				Expect(statefulSet.ObjectMeta.Name).To(Equal(instance.Name))
 				Expect(statefulSet.ObjectMeta.Namespace).To(Equal(instance.Namespace))
+				Expect(statefulSet.ObjectMeta.Annotations).To(HaveKey("rabbitmq.com/podTemplateHash"))
+				delete(statefulSet.ObjectMeta.Annotations, "rabbitmq.com/podTemplateHash")
 				Expect(statefulSet.ObjectMeta.Annotations).To(Equal(map[string]string{"my-key": "my-value"}))
 				Expect(statefulSet.ObjectMeta.Labels).To(Equal(map[string]string{
 					"new-label-key":               "new-label-value",
@@ -0,0 +1,315 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+// Package setup prepares the configuration files of a RabbitMQ node before it starts.
+// It runs in the setup-container of the RabbitMQ Pods, from the operator image.
+package setup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultConfigDir        = "/tmp/rabbitmq"
+	DefaultPluginsConfigDir = "/tmp/rabbitmq-plugins"
+	DefaultErlangCookieDir  = "/tmp/erlang-cookie-secret"
+	DefaultEtcDir           = "/etc/rabbitmq"
+	DefaultHomeDir          = "/var/lib/rabbitmq"
+	DefaultMnesiaDir        = "/var/lib/rabbitmq/mnesia"
+	// attempts to download a community plugin before the setup fails
+	pluginDownloadAttempts = 3
+)
+
+// Config holds the directories the setup copies from and to, and the settings it adds to rabbitmq.conf
+type Config struct {
+	// ConfigDir is where the server ConfigMap is mounted
+	ConfigDir string
+	// PluginsConfigDir is where the plugins ConfigMap is mounted
+	PluginsConfigDir string
+	// ErlangCookieDir is where the Erlang cookie Secret is mounted
+	ErlangCookieDir string
+	// EtcDir is the configuration directory of RabbitMQ
+	EtcDir string
+	// HomeDir is the home directory of RabbitMQ, which holds the Erlang cookie
+	HomeDir string
+	// MnesiaDir is the data directory of RabbitMQ
+	MnesiaDir string
+	// Owner of the copied files and group of MnesiaDir; nil leaves them to the user running the setup
+	UID, GID *int
+	// Hostname of the node, advertised with StreamAdvertisedDomain
+	Hostname string
+	// StreamAdvertisedDomain is the domain the stream plugin advertises the node under; not advertised when empty
+	StreamAdvertisedDomain string
+	// StreamAdvertisedTLS advertises the TLS port of the stream plugin
+	StreamAdvertisedTLS bool
+	// NodeDomain is the domain of the node name after its hostname, which names the data directory of the node
+	NodeDomain string
+	// MessageStoreDir is the volume the classic message store is linked to; not linked when empty
+	MessageStoreDir string
+	// CommunityPluginsDir is the volume the CommunityPlugins are downloaded to
+	CommunityPluginsDir string
+	// CommunityPlugins are the .ez files of community plugins to download
+	CommunityPlugins []CommunityPlugin
+	// Warnings receives the problems that do not stop the setup; they are discarded when nil
+	Warnings io.Writer
+}
+
+// CommunityPlugin is the .ez file of a community plugin downloaded from a URL
+type CommunityPlugin struct {
+	// File is the name the plugin is stored under in CommunityPluginsDir
+	File string
+	// SHA256 is the hex-encoded checksum the downloaded file must match
+	SHA256 string
+	URL    string
+}
+
+// DefaultConfig returns the directories of the setup-container and the RabbitMQ image
+func DefaultConfig() Config {
+	return Config{
+		ConfigDir:        DefaultConfigDir,
+		PluginsConfigDir: DefaultPluginsConfigDir,
+		ErlangCookieDir:  DefaultErlangCookieDir,
+		EtcDir:           DefaultEtcDir,
+		HomeDir:          DefaultHomeDir,
+		MnesiaDir:        DefaultMnesiaDir,
+	}
+}
+
+type configFile struct {
+	source, destination string
+	mode                os.FileMode
+	optional            bool
+}
+
+// Run copies the configuration files, the enabled plugins and the Erlang cookie into the directories of RabbitMQ.
+// It stops at the first failure, so that the node never starts with an incomplete configuration.
+// Malformed lines of rabbitmq.conf are only reported as warnings, as RabbitMQ itself validates its configuration.
+func Run(config Config) error {
+	rabbitmqConf, err := config.rabbitmqConf()
+	if err != nil {
+		return err
+	}
+	if err := ValidateRabbitmqConf(rabbitmqConf); err != nil && config.Warnings != nil {
+		fmt.Fprintf(config.Warnings, "warning: %v\n", err)
+	}
+	if err := config.install(rabbitmqConf, filepath.Join(config.EtcDir, "rabbitmq.conf"), 0644); err != nil {
+		return err
+	}
+
+	files := []configFile{
+		{
+			source:      filepath.Join(config.ConfigDir, "advanced.config"),
+			destination: filepath.Join(config.EtcDir, "advanced.config"),
+			mode:        0644,
+			optional:    true,
+		},
+		{
+			source:      filepath.Join(config.ConfigDir, "rabbitmq-env.conf"),
+			destination: filepath.Join(config.EtcDir, "rabbitmq-env.conf"),
+			mode:        0644,
+			optional:    true,
+		},
+		{
+			source:      filepath.Join(config.PluginsConfigDir, "enabled_plugins"),
+			destination: filepath.Join(config.EtcDir, "enabled_plugins"),
+			mode:        0644,
+		},
+		{
+			// the Erlang runtime refuses to start when the cookie can be read by other users
+			source:      filepath.Join(config.ErlangCookieDir, ".erlang.cookie"),
+			destination: filepath.Join(config.HomeDir, ".erlang.cookie"),
+			mode:        0600,
+		},
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file.source)
+		if os.IsNotExist(err) && file.optional {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.source, err)
+		}
+		if err := config.install(content, file.destination, file.mode); err != nil {
+			return err
+		}
+	}
+
+	if config.GID != nil {
+		if err := os.Chown(config.MnesiaDir, -1, *config.GID); err != nil {
+			return fmt.Errorf("failed to change the group of %s to %d: %w", config.MnesiaDir, *config.GID, err)
+		}
+	}
+
+	if config.MessageStoreDir != "" {
+		if err := config.linkMessageStore(); err != nil {
+			return err
+		}
+	}
+
+	for _, plugin := range config.CommunityPlugins {
+		if err := config.downloadCommunityPlugin(plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadCommunityPlugin downloads a community plugin into CommunityPluginsDir, and only stores it when its checksum matches
+func (config Config) downloadCommunityPlugin(plugin CommunityPlugin) error {
+	var content []byte
+	var err error
+	for attempt := 1; attempt <= pluginDownloadAttempts; attempt++ {
+		if content, err = download(plugin.URL); err == nil || attempt == pluginDownloadAttempts {
+			break
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download community plugin %s from %s: %w", plugin.File, plugin.URL, err)
+	}
+
+	sum := sha256.Sum256(content)
+	if checksum := hex.EncodeToString(sum[:]); !strings.EqualFold(checksum, plugin.SHA256) {
+		return fmt.Errorf("community plugin %s from %s has checksum %s, expected %s", plugin.File, plugin.URL, checksum, plugin.SHA256)
+	}
+	return config.install(content, filepath.Join(config.CommunityPluginsDir, plugin.File), 0644)
+}
+
+func download(url string) ([]byte, error) {
+	client := http.Client{Timeout: 5 * time.Minute}
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response %s", response.Status)
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+// linkMessageStore links the msg_stores directory of the node to MessageStoreDir, on the first start of the node only
+func (config Config) linkMessageStore() error {
+	nodeDir := filepath.Join(config.MnesiaDir, fmt.Sprintf("rabbit@%s.%s", config.Hostname, config.NodeDomain))
+	link := filepath.Join(nodeDir, "msg_stores")
+
+	info, err := os.Lstat(link)
+	if err == nil {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("%s already holds the classic message store of the node, which cannot be moved to the volume at %s", link, config.MessageStoreDir)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", link, err)
+	}
+
+	if err := os.MkdirAll(nodeDir, 0755); err != nil {
+		return fmt.Errorf("failed to create the data directory %s: %w", nodeDir, err)
+	}
+	if err := os.Symlink(config.MessageStoreDir, link); err != nil {
+		return fmt.Errorf("failed to link %s to %s: %w", link, config.MessageStoreDir, err)
+	}
+	if config.UID != nil && config.GID != nil {
+		if err := os.Lchown(link, *config.UID, *config.GID); err != nil {
+			return fmt.Errorf("failed to change the owner of %s: %w", link, err)
+		}
+		if err := os.Chown(nodeDir, *config.UID, *config.GID); err != nil {
+			return fmt.Errorf("failed to change the owner of %s: %w", nodeDir, err)
+		}
+	}
+	return nil
+}
+
+// rabbitmqConf returns rabbitmq.conf from the server ConfigMap, with the advertised address of the stream plugin appended
+func (config Config) rabbitmqConf() ([]byte, error) {
+	source := filepath.Join(config.ConfigDir, "rabbitmq.conf")
+	content, err := ioutil.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	content = append(content, '\n')
+
+	if config.StreamAdvertisedDomain != "" {
+		content = append(content, fmt.Sprintf("stream.advertised_host = %s.%s\n", config.Hostname, config.StreamAdvertisedDomain)...)
+		content = append(content, "stream.advertised_port = 5552\n"...)
+		if config.StreamAdvertisedTLS {
+			content = append(content, "stream.advertised_tls_port = 5551\n"...)
+		}
+	}
+	return content, nil
+}
+
+// install writes content to destination with the given permissions, owned by the configured user and group
+func (config Config) install(content []byte, destination string, mode os.FileMode) error {
+	// a file left by a previous run of the setup-container may not be writable, e.g. the Erlang cookie owned by RabbitMQ
+	if err := os.Remove(destination); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", destination, err)
+	}
+	if err := ioutil.WriteFile(destination, content, mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", destination, err)
+	}
+	// the umask of the setup-container applies to WriteFile
+	if err := os.Chmod(destination, mode); err != nil {
+		return fmt.Errorf("failed to set the permissions of %s to %o: %w", destination, mode, err)
+	}
+	if config.UID != nil || config.GID != nil {
+		uid, gid := -1, -1
+		if config.UID != nil {
+			uid = *config.UID
+		}
+		if config.GID != nil {
+			gid = *config.GID
+		}
+		if err := os.Chown(destination, uid, gid); err != nil {
+			return fmt.Errorf("failed to change the owner of %s to %d:%d: %w", destination, uid, gid, err)
+		}
+	}
+	return nil
+}
+
+// ValidateRabbitmqConf reports the lines of rabbitmq.conf that are obviously malformed: settings without a key, or with whitespace in the key.
+// It is not a parser: lines without = and indented lines may continue the value of the previous setting, and values may contain =.
+// All malformed lines are reported, numbered as in the rabbitmq.conf of the server ConfigMap.
+func ValidateRabbitmqConf(content []byte) error {
+	var problems []string
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		continuation := strings.TrimLeft(line, " \t") != line
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || continuation {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		switch {
+		case key == "":
+			problems = append(problems, fmt.Sprintf("line %d: missing key before =, got %q", i+1, line))
+		case strings.ContainsAny(key, " \t"):
+			problems = append(problems, fmt.Sprintf("line %d: key %q contains whitespace", i+1, key))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("malformed rabbitmq.conf, check spec.rabbitmq.additionalConfig of the RabbitmqCluster:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
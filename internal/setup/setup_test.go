@@ -0,0 +1,227 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package setup_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/setup"
+)
+
+var _ = Describe("Setup", func() {
+	var (
+		root   string
+		config setup.Config
+	)
+
+	writeFile := func(path, content string) {
+		ExpectWithOffset(1, ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+	readFile := func(path string) string {
+		content, err := ioutil.ReadFile(path)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return string(content)
+	}
+	fileMode := func(path string) os.FileMode {
+		info, err := os.Stat(path)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return info.Mode().Perm()
+	}
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "setup-container")
+		Expect(err).NotTo(HaveOccurred())
+
+		config = setup.Config{
+			ConfigDir:        filepath.Join(root, "server-conf"),
+			PluginsConfigDir: filepath.Join(root, "plugins-conf"),
+			ErlangCookieDir:  filepath.Join(root, "erlang-cookie-secret"),
+			EtcDir:           filepath.Join(root, "etc"),
+			HomeDir:          filepath.Join(root, "home"),
+			MnesiaDir:        filepath.Join(root, "home", "mnesia"),
+			Hostname:         "foo-rabbitmq-server-0",
+		}
+		for _, dir := range []string{config.ConfigDir, config.PluginsConfigDir, config.ErlangCookieDir, config.EtcDir, config.MnesiaDir} {
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		}
+		writeFile(filepath.Join(config.ConfigDir, "rabbitmq.conf"), "# managed by the operator\ncluster_name = foo\nqueue_master_locator = min-masters")
+		writeFile(filepath.Join(config.PluginsConfigDir, "enabled_plugins"), "[rabbitmq_peer_discovery_k8s,rabbitmq_prometheus,rabbitmq_management].")
+		writeFile(filepath.Join(config.ErlangCookieDir, ".erlang.cookie"), "secret-cookie")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(root)).To(Succeed())
+	})
+
+	It("copies the configuration files and the Erlang cookie", func() {
+		Expect(setup.Run(config)).To(Succeed())
+
+		Expect(readFile(filepath.Join(config.EtcDir, "rabbitmq.conf"))).To(Equal("# managed by the operator\ncluster_name = foo\nqueue_master_locator = min-masters\n"))
+		Expect(fileMode(filepath.Join(config.EtcDir, "rabbitmq.conf"))).To(Equal(os.FileMode(0644)))
+		Expect(readFile(filepath.Join(config.EtcDir, "enabled_plugins"))).To(Equal("[rabbitmq_peer_discovery_k8s,rabbitmq_prometheus,rabbitmq_management]."))
+		Expect(readFile(filepath.Join(config.HomeDir, ".erlang.cookie"))).To(Equal("secret-cookie"))
+		Expect(fileMode(filepath.Join(config.HomeDir, ".erlang.cookie"))).To(Equal(os.FileMode(0600)))
+	})
+
+	It("copies advanced.config and rabbitmq-env.conf only when they are configured", func() {
+		Expect(setup.Run(config)).To(Succeed())
+		Expect(filepath.Join(config.EtcDir, "advanced.config")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(config.EtcDir, "rabbitmq-env.conf")).NotTo(BeAnExistingFile())
+
+		writeFile(filepath.Join(config.ConfigDir, "advanced.config"), "[{rabbit, []}].")
+		writeFile(filepath.Join(config.ConfigDir, "rabbitmq-env.conf"), "USE_LONGNAME=true")
+		Expect(setup.Run(config)).To(Succeed())
+		Expect(readFile(filepath.Join(config.EtcDir, "advanced.config"))).To(Equal("[{rabbit, []}]."))
+		Expect(readFile(filepath.Join(config.EtcDir, "rabbitmq-env.conf"))).To(Equal("USE_LONGNAME=true"))
+	})
+
+	It("replaces the files of a previous run", func() {
+		cookie := filepath.Join(config.HomeDir, ".erlang.cookie")
+		Expect(os.MkdirAll(config.HomeDir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(cookie, []byte("old-cookie"), 0400)).To(Succeed())
+
+		Expect(setup.Run(config)).To(Succeed())
+		Expect(readFile(cookie)).To(Equal("secret-cookie"))
+		Expect(fileMode(cookie)).To(Equal(os.FileMode(0600)))
+	})
+
+	It("sets the owner of the files", func() {
+		uid, gid := os.Getuid(), os.Getgid()
+		config.UID, config.GID = &uid, &gid
+		Expect(setup.Run(config)).To(Succeed())
+	})
+
+	It("advertises the address of the node to stream clients", func() {
+		config.StreamAdvertisedDomain = "foo-rabbitmq-headless.foo-namespace.svc"
+		config.StreamAdvertisedTLS = true
+		Expect(setup.Run(config)).To(Succeed())
+
+		Expect(readFile(filepath.Join(config.EtcDir, "rabbitmq.conf"))).To(HaveSuffix("queue_master_locator = min-masters\n" +
+			"stream.advertised_host = foo-rabbitmq-server-0.foo-rabbitmq-headless.foo-namespace.svc\n" +
+			"stream.advertised_port = 5552\n" +
+			"stream.advertised_tls_port = 5551\n"))
+	})
+
+	It("writes rabbitmq.conf and warns when it has malformed lines", func() {
+		var warnings bytes.Buffer
+		config.Warnings = &warnings
+		writeFile(filepath.Join(config.ConfigDir, "rabbitmq.conf"), "cluster_name = foo\nlog console level = debug")
+
+		Expect(setup.Run(config)).To(Succeed())
+		Expect(warnings.String()).To(ContainSubstring("spec.rabbitmq.additionalConfig"))
+		Expect(warnings.String()).To(ContainSubstring(`line 2: key "log console level" contains whitespace`))
+		Expect(filepath.Join(config.EtcDir, "rabbitmq.conf")).To(BeAnExistingFile())
+	})
+
+	It("fails when a required file is missing", func() {
+		Expect(os.Remove(filepath.Join(config.ErlangCookieDir, ".erlang.cookie"))).To(Succeed())
+
+		Expect(setup.Run(config)).To(MatchError(ContainSubstring("failed to read " + filepath.Join(config.ErlangCookieDir, ".erlang.cookie"))))
+	})
+
+	Context("message store volume", func() {
+		var nodeDir string
+
+		BeforeEach(func() {
+			config.NodeDomain = "foo-rabbitmq-headless.foo-namespace"
+			config.MessageStoreDir = filepath.Join(root, "msg-store")
+			nodeDir = filepath.Join(config.MnesiaDir, "rabbit@foo-rabbitmq-server-0.foo-rabbitmq-headless.foo-namespace")
+		})
+
+		It("links the message store of the node to the volume", func() {
+			Expect(setup.Run(config)).To(Succeed())
+
+			target, err := os.Readlink(filepath.Join(nodeDir, "msg_stores"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target).To(Equal(config.MessageStoreDir))
+
+			Expect(setup.Run(config)).To(Succeed())
+		})
+
+		It("fails when the node already has a message store", func() {
+			Expect(os.MkdirAll(filepath.Join(nodeDir, "msg_stores"), 0755)).To(Succeed())
+
+			Expect(setup.Run(config)).To(MatchError(ContainSubstring("already holds the classic message store")))
+		})
+	})
+
+	Context("community plugins", func() {
+		var (
+			server  *httptest.Server
+			content = []byte("not really an .ez archive")
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(content)
+			}))
+			config.CommunityPluginsDir = filepath.Join(root, "community-plugins")
+			Expect(os.MkdirAll(config.CommunityPluginsDir, 0755)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("stores the plugin when the checksum matches", func() {
+			sum := sha256.Sum256(content)
+			config.CommunityPlugins = []setup.CommunityPlugin{{
+				File:   "rabbitmq_local-1.0.0.ez",
+				SHA256: hex.EncodeToString(sum[:]),
+				URL:    server.URL + "/rabbitmq_local-1.0.0.ez",
+			}}
+			Expect(setup.Run(config)).To(Succeed())
+
+			plugin := filepath.Join(config.CommunityPluginsDir, "rabbitmq_local-1.0.0.ez")
+			Expect(readFile(plugin)).To(Equal(string(content)))
+			Expect(fileMode(plugin)).To(Equal(os.FileMode(0644)))
+		})
+
+		It("fails without storing the plugin when the checksum does not match", func() {
+			config.CommunityPlugins = []setup.CommunityPlugin{{
+				File:   "rabbitmq_local-1.0.0.ez",
+				SHA256: "ab",
+				URL:    server.URL + "/rabbitmq_local-1.0.0.ez",
+			}}
+			Expect(setup.Run(config)).To(MatchError(ContainSubstring("expected ab")))
+			Expect(filepath.Join(config.CommunityPluginsDir, "rabbitmq_local-1.0.0.ez")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("ValidateRabbitmqConf", func() {
+		It("accepts blank lines, comments and settings", func() {
+			Expect(setup.ValidateRabbitmqConf([]byte("\n# comment\n  \nlisteners.tcp.default = 5672\ndefault_permissions.configure = .*\n"))).To(Succeed())
+		})
+
+		It("accepts values containing = and continuation lines", func() {
+			Expect(setup.ValidateRabbitmqConf([]byte("auth_oauth2.additional_scopes_key = a=b\n" +
+				"log.file.formatter.json.field_map = time:ts level msg\n" +
+				"  pid:- mfa:-\n" +
+				"management.headers.content_security_policy = default-src 'self'\n"))).To(Succeed())
+		})
+
+		It("reports every malformed line", func() {
+			err := setup.ValidateRabbitmqConf([]byte("= 5672\nlisteners tcp = 5672\ncluster_name = foo"))
+			Expect(err).To(MatchError(ContainSubstring(`line 1: missing key before =`)))
+			Expect(err).To(MatchError(ContainSubstring(`line 2: key "listeners tcp" contains whitespace`)))
+			Expect(err.Error()).NotTo(ContainSubstring("line 3"))
+		})
+	})
+})
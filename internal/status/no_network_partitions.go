@@ -0,0 +1,62 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rabbitmq/cluster-operator/internal/management"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func NoNetworkPartitionsCondition(nodes []management.Node,
+	oldCondition *RabbitmqClusterCondition) RabbitmqClusterCondition {
+
+	var partitions []string
+	condition := newRabbitmqClusterCondition(NoNetworkPartitions)
+	if oldCondition != nil {
+		condition.LastTransitionTime = oldCondition.LastTransitionTime
+	}
+
+	if len(nodes) == 0 {
+		condition.Status = corev1.ConditionUnknown
+		condition.Reason = "NodeStatusUnavailable"
+		condition.Message = "Could not retrieve node status from the management API"
+		goto assignLastTransitionTime
+	}
+
+	for _, node := range nodes {
+		if len(node.Partitions) > 0 {
+			partitions = append(partitions, fmt.Sprintf("%s cannot reach %s", node.Name, strings.Join(node.Partitions, ", ")))
+		}
+	}
+
+	if len(partitions) > 0 {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "NetworkPartitionDetected"
+		condition.Message = strings.Join(partitions, "; ")
+		goto assignLastTransitionTime
+	}
+
+	condition.Status = corev1.ConditionTrue
+	condition.Reason = "NoPartitionsDetected"
+
+assignLastTransitionTime:
+	if oldCondition == nil || oldCondition.Status != condition.Status {
+		condition.LastTransitionTime = metav1.Time{
+			Time: time.Now(),
+		}
+	}
+
+	return condition
+}
@@ -0,0 +1,112 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/management"
+	rabbitmqstatus "github.com/rabbitmq/cluster-operator/internal/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NoNetworkPartitions", func() {
+	var (
+		nodes             []management.Node
+		existingCondition *rabbitmqstatus.RabbitmqClusterCondition
+	)
+
+	BeforeEach(func() {
+		nodes = []management.Node{
+			{Name: "rabbit@node-0", Running: true},
+			{Name: "rabbit@node-1", Running: true},
+			{Name: "rabbit@node-2", Running: true},
+		}
+		existingCondition = nil
+	})
+
+	Context("condition status and reason", func() {
+		When("no node reports a partition", func() {
+			It("returns a condition with state true", func() {
+				condition := rabbitmqstatus.NoNetworkPartitionsCondition(nodes, existingCondition)
+
+				Expect(condition.Type).To(Equal(rabbitmqstatus.NoNetworkPartitions))
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("NoPartitionsDetected"))
+			})
+		})
+
+		When("nodes report partitions", func() {
+			BeforeEach(func() {
+				nodes[0].Partitions = []string{"rabbit@node-2"}
+				nodes[1].Partitions = []string{"rabbit@node-2"}
+				nodes[2].Partitions = []string{"rabbit@node-0", "rabbit@node-1"}
+			})
+
+			It("returns a condition with state false listing the partitions", func() {
+				condition := rabbitmqstatus.NoNetworkPartitionsCondition(nodes, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("NetworkPartitionDetected"))
+				Expect(condition.Message).To(Equal("rabbit@node-0 cannot reach rabbit@node-2; " +
+					"rabbit@node-1 cannot reach rabbit@node-2; " +
+					"rabbit@node-2 cannot reach rabbit@node-0, rabbit@node-1"))
+			})
+		})
+
+		When("node status is unavailable", func() {
+			It("returns a condition with state unknown", func() {
+				condition := rabbitmqstatus.NoNetworkPartitionsCondition(nil, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionUnknown))
+				Expect(condition.Reason).To(Equal("NodeStatusUnavailable"))
+				Expect(condition.Message).NotTo(BeEmpty())
+			})
+		})
+	})
+
+	Context("condition transitions", func() {
+		var previousConditionTime time.Time
+
+		BeforeEach(func() {
+			previousConditionTime = time.Date(2020, 2, 2, 8, 0, 0, 0, time.UTC)
+			existingCondition = &rabbitmqstatus.RabbitmqClusterCondition{
+				Status: corev1.ConditionTrue,
+				LastTransitionTime: metav1.Time{
+					Time: previousConditionTime,
+				},
+			}
+		})
+
+		When("remains true", func() {
+			It("keeps the transition timestamp", func() {
+				condition := rabbitmqstatus.NoNetworkPartitionsCondition(nodes, existingCondition)
+
+				Expect(condition.LastTransitionTime.Time).To(Equal(previousConditionTime))
+			})
+		})
+
+		When("transitions to false", func() {
+			BeforeEach(func() {
+				nodes[0].Partitions = []string{"rabbit@node-1"}
+			})
+
+			It("updates the transition timestamp", func() {
+				condition := rabbitmqstatus.NoNetworkPartitionsCondition(nodes, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.LastTransitionTime.Time.After(previousConditionTime)).To(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,79 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rabbitmq/cluster-operator/internal/management"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func NoResourceAlarmsCondition(nodes []management.Node,
+	oldCondition *RabbitmqClusterCondition) RabbitmqClusterCondition {
+
+	var memoryAlarms, diskAlarms, messages []string
+	condition := newRabbitmqClusterCondition(NoResourceAlarms)
+	if oldCondition != nil {
+		condition.LastTransitionTime = oldCondition.LastTransitionTime
+	}
+
+	if len(nodes) == 0 {
+		condition.Status = corev1.ConditionUnknown
+		condition.Reason = "NodeStatusUnavailable"
+		condition.Message = "Could not retrieve node status from the management API"
+		goto assignLastTransitionTime
+	}
+
+	for _, node := range nodes {
+		if node.MemAlarm {
+			memoryAlarms = append(memoryAlarms, node.Name)
+		}
+		if node.DiskFreeAlarm {
+			diskAlarms = append(diskAlarms, node.Name)
+		}
+	}
+
+	if len(memoryAlarms) > 0 {
+		messages = append(messages, fmt.Sprintf("memory alarm raised on %s", strings.Join(memoryAlarms, ", ")))
+	}
+	if len(diskAlarms) > 0 {
+		messages = append(messages, fmt.Sprintf("disk free alarm raised on %s", strings.Join(diskAlarms, ", ")))
+	}
+
+	if len(messages) > 0 {
+		condition.Status = corev1.ConditionFalse
+		switch {
+		case len(memoryAlarms) > 0 && len(diskAlarms) > 0:
+			condition.Reason = "MemoryAndDiskAlarms"
+		case len(memoryAlarms) > 0:
+			condition.Reason = "MemoryAlarm"
+		default:
+			condition.Reason = "DiskAlarm"
+		}
+		condition.Message = strings.Join(messages, "; ")
+		goto assignLastTransitionTime
+	}
+
+	condition.Status = corev1.ConditionTrue
+	condition.Reason = "NoAlarmsRaised"
+
+assignLastTransitionTime:
+	if oldCondition == nil || oldCondition.Status != condition.Status {
+		condition.LastTransitionTime = metav1.Time{
+			Time: time.Now(),
+		}
+	}
+
+	return condition
+}
@@ -0,0 +1,136 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/management"
+	rabbitmqstatus "github.com/rabbitmq/cluster-operator/internal/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NoResourceAlarms", func() {
+	var (
+		nodes             []management.Node
+		existingCondition *rabbitmqstatus.RabbitmqClusterCondition
+	)
+
+	BeforeEach(func() {
+		nodes = []management.Node{
+			{Name: "rabbit@node-0", Running: true},
+			{Name: "rabbit@node-1", Running: true},
+		}
+		existingCondition = nil
+	})
+
+	Context("condition status and reason", func() {
+		When("no alarm is raised", func() {
+			It("returns a condition with state true", func() {
+				condition := rabbitmqstatus.NoResourceAlarmsCondition(nodes, existingCondition)
+
+				Expect(condition.Type).To(Equal(rabbitmqstatus.NoResourceAlarms))
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("NoAlarmsRaised"))
+			})
+		})
+
+		When("a memory alarm is raised", func() {
+			BeforeEach(func() {
+				nodes[1].MemAlarm = true
+			})
+
+			It("returns a condition with state false", func() {
+				condition := rabbitmqstatus.NoResourceAlarmsCondition(nodes, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("MemoryAlarm"))
+				Expect(condition.Message).To(Equal("memory alarm raised on rabbit@node-1"))
+			})
+		})
+
+		When("a disk free alarm is raised", func() {
+			BeforeEach(func() {
+				nodes[0].DiskFreeAlarm = true
+				nodes[1].DiskFreeAlarm = true
+			})
+
+			It("returns a condition with state false", func() {
+				condition := rabbitmqstatus.NoResourceAlarmsCondition(nodes, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("DiskAlarm"))
+				Expect(condition.Message).To(Equal("disk free alarm raised on rabbit@node-0, rabbit@node-1"))
+			})
+		})
+
+		When("both memory and disk free alarms are raised", func() {
+			BeforeEach(func() {
+				nodes[0].MemAlarm = true
+				nodes[1].DiskFreeAlarm = true
+			})
+
+			It("returns a condition with state false", func() {
+				condition := rabbitmqstatus.NoResourceAlarmsCondition(nodes, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("MemoryAndDiskAlarms"))
+				Expect(condition.Message).To(Equal("memory alarm raised on rabbit@node-0; disk free alarm raised on rabbit@node-1"))
+			})
+		})
+
+		When("node status is unavailable", func() {
+			It("returns a condition with state unknown", func() {
+				condition := rabbitmqstatus.NoResourceAlarmsCondition(nil, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionUnknown))
+				Expect(condition.Reason).To(Equal("NodeStatusUnavailable"))
+			})
+		})
+	})
+
+	Context("condition transitions", func() {
+		var previousConditionTime time.Time
+
+		BeforeEach(func() {
+			previousConditionTime = time.Date(2020, 2, 2, 8, 0, 0, 0, time.UTC)
+			existingCondition = &rabbitmqstatus.RabbitmqClusterCondition{
+				Status: corev1.ConditionFalse,
+				LastTransitionTime: metav1.Time{
+					Time: previousConditionTime,
+				},
+			}
+		})
+
+		When("remains false", func() {
+			BeforeEach(func() {
+				nodes[0].MemAlarm = true
+			})
+
+			It("keeps the transition timestamp", func() {
+				condition := rabbitmqstatus.NoResourceAlarmsCondition(nodes, existingCondition)
+
+				Expect(condition.LastTransitionTime.Time).To(Equal(previousConditionTime))
+			})
+		})
+
+		When("transitions to true", func() {
+			It("updates the transition timestamp", func() {
+				condition := rabbitmqstatus.NoResourceAlarmsCondition(nodes, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.LastTransitionTime.Time.After(previousConditionTime)).To(BeTrue())
+			})
+		})
+	})
+})
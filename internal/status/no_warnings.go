@@ -42,6 +42,20 @@ func NoWarningsCondition(resources []runtime.Object, oldCondition *RabbitmqClust
 				goto assignLastTransitionTime
 			}
 
+			if ephemeralStorage(resource) && persistentVolumeClaimTemplate(resource) {
+				condition.Status = corev1.ConditionFalse
+				condition.Reason = "PersistenceCannotBeDisabled"
+				condition.Message = "spec.persistence.enabled is false, but RabbitMQ data is still stored in PersistentVolumeClaims: the volume claim templates of the StatefulSet cannot change"
+				goto assignLastTransitionTime
+			}
+
+			if ephemeralStorage(resource) {
+				condition.Status = corev1.ConditionFalse
+				condition.Reason = "EphemeralStorage"
+				condition.Message = "RabbitMQ data is stored in an emptyDir volume and is lost when a Pod is deleted"
+				goto assignLastTransitionTime
+			}
+
 			condition.Status = corev1.ConditionTrue
 			condition.Reason = "NoWarnings"
 		}
@@ -56,3 +70,24 @@ assignLastTransitionTime:
 
 	return condition
 }
+
+// ephemeralStorage reports whether the RabbitMQ data directory of the StatefulSet is an emptyDir volume
+func ephemeralStorage(sts *appsv1.StatefulSet) bool {
+	for _, volume := range sts.Spec.Template.Spec.Volumes {
+		if volume.Name == "persistence" && volume.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// persistentVolumeClaimTemplate reports whether the StatefulSet has a volume claim template for the RabbitMQ data directory,
+// which takes precedence over the emptyDir volume of its Pod template
+func persistentVolumeClaimTemplate(sts *appsv1.StatefulSet) bool {
+	for _, claim := range sts.Spec.VolumeClaimTemplates {
+		if claim.Name == "persistence" {
+			return true
+		}
+	}
+	return false
+}
@@ -65,6 +65,57 @@ var _ = Describe("NoWarnings", func() {
 		})
 	})
 
+	It("is false if RabbitMQ data is stored in an emptyDir volume", func() {
+		sts := &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{}},
+						Volumes: []corev1.Volume{
+							{
+								Name:         "persistence",
+								VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+							},
+						},
+					},
+				},
+			},
+		}
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{sts}, nil)
+
+		By("having status false and reason message", func() {
+			Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("EphemeralStorage"))
+			Expect(condition.Message).To(ContainSubstring("lost when a Pod is deleted"))
+		})
+	})
+
+	It("is false if persistence was disabled after the StatefulSet was created with a PersistentVolumeClaim template", func() {
+		sts := &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{}},
+						Volumes: []corev1.Volume{
+							{
+								Name:         "persistence",
+								VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+							},
+						},
+					},
+				},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "persistence"}}},
+			},
+		}
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{sts}, nil)
+
+		By("having status false and reason message", func() {
+			Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("PersistenceCannotBeDisabled"))
+			Expect(condition.Message).To(ContainSubstring("cannot change"))
+		})
+	})
+
 	It("is unknown when the StatefulSet does not exist", func() {
 		var sts *appsv1.StatefulSet = nil
 		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{sts}, nil)
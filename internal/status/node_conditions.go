@@ -0,0 +1,28 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status
+
+import (
+	"github.com/rabbitmq/cluster-operator/internal/management"
+)
+
+// UpdateNodeConditions updates the conditions derived from the node states reported by the management API.
+// A nil list of nodes sets them to Unknown.
+func UpdateNodeConditions(conditions []RabbitmqClusterCondition, nodes []management.Node) {
+	for i := range conditions {
+		oldCondition := conditions[i].DeepCopy()
+		switch oldCondition.Type {
+		case NoNetworkPartitions:
+			conditions[i] = NoNetworkPartitionsCondition(nodes, oldCondition)
+		case NoResourceAlarms:
+			conditions[i] = NoResourceAlarmsCondition(nodes, oldCondition)
+		}
+	}
+}
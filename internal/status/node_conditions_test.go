@@ -0,0 +1,54 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/management"
+	rabbitmqstatus "github.com/rabbitmq/cluster-operator/internal/status"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("UpdateNodeConditions", func() {
+	var conditions []rabbitmqstatus.RabbitmqClusterCondition
+
+	BeforeEach(func() {
+		conditions = []rabbitmqstatus.RabbitmqClusterCondition{
+			rabbitmqstatus.ReconcileSuccessCondition(corev1.ConditionTrue, "Success", ""),
+			rabbitmqstatus.NoNetworkPartitionsCondition(nil, nil),
+			rabbitmqstatus.NoResourceAlarmsCondition(nil, nil),
+		}
+	})
+
+	It("sets the node conditions from the management API", func() {
+		rabbitmqstatus.UpdateNodeConditions(conditions, []management.Node{
+			{Name: "rabbit@node-0", Partitions: []string{"rabbit@node-1"}},
+			{Name: "rabbit@node-1"},
+		})
+		Expect(conditions[1].Type).To(Equal(rabbitmqstatus.NoNetworkPartitions))
+		Expect(conditions[1].Status).To(Equal(corev1.ConditionFalse))
+		Expect(conditions[2].Type).To(Equal(rabbitmqstatus.NoResourceAlarms))
+		Expect(conditions[2].Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("sets the node conditions to Unknown without nodes", func() {
+		rabbitmqstatus.UpdateNodeConditions(conditions, []management.Node{{Name: "rabbit@node-0"}})
+		rabbitmqstatus.UpdateNodeConditions(conditions, nil)
+		Expect(conditions[1].Status).To(Equal(corev1.ConditionUnknown))
+		Expect(conditions[2].Status).To(Equal(corev1.ConditionUnknown))
+	})
+
+	It("leaves the other conditions unchanged", func() {
+		rabbitmqstatus.UpdateNodeConditions(conditions, nil)
+		Expect(conditions[0].Type).To(Equal(rabbitmqstatus.ReconcileSuccess))
+		Expect(conditions[0].Status).To(Equal(corev1.ConditionTrue))
+	})
+})
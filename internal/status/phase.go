@@ -0,0 +1,110 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status
+
+// ClusterPhase is a high level summary of where the RabbitmqCluster is in its lifecycle.
+// It is published in status.clusterStatus.
+//
+// The phase is recomputed on every reconciliation from the previous phase and a PhaseObservation.
+// The rules below are evaluated in order, the first matching rule wins:
+//
+//	any phase      -> Deleting          the RabbitmqCluster has a deletion timestamp; Deleting is terminal
+//	any phase      -> Failed            ReconcileSuccess is False
+//	any phase      -> MigratingStorage  the cluster has been Running and its nodes are being moved to PersistentVolumes
+//	                                    of a new StorageClass, during which the StatefulSet is deleted and recreated
+//	any phase      -> Creating          the StatefulSet does not exist yet
+//
+// Whether the cluster has been Running is tracked separately from the phase (status.firstRunningTime),
+// as a Failed or Creating cluster may or may not have been Running before.
+//
+// Before the cluster has been Running for the first time:
+//
+//	-> Running       all replicas are ready and the cluster is healthy
+//	-> Initializing  otherwise
+//
+// Once the cluster has been Running:
+//
+//	-> ScalingDown       the StatefulSet runs more Pods than desired
+//	-> Upgrading         a rollout is in progress and some Pods run a different image
+//	-> Updating          a rollout is in progress
+//	-> Degraded          not all replicas are ready or the cluster is unhealthy
+//	-> Running           otherwise
+type ClusterPhase string
+
+const (
+	PhaseCreating         ClusterPhase = "Creating"
+	PhaseInitializing     ClusterPhase = "Initializing"
+	PhaseRunning          ClusterPhase = "Running"
+	PhaseUpdating         ClusterPhase = "Updating"
+	PhaseUpgrading        ClusterPhase = "Upgrading"
+	PhaseScalingDown      ClusterPhase = "ScalingDown"
+	PhaseMigratingStorage ClusterPhase = "MigratingStorage"
+	PhaseDegraded         ClusterPhase = "Degraded"
+	PhaseDeleting         ClusterPhase = "Deleting"
+	PhaseFailed           ClusterPhase = "Failed"
+)
+
+// PhaseObservation is the observed state of a RabbitmqCluster and its children the next phase is derived from.
+// +kubebuilder:object:generate=false
+type PhaseObservation struct {
+	// The RabbitmqCluster has been marked for deletion
+	Deleting bool
+	// The last reconciliation failed to create or update a child resource
+	ReconcileFailed   bool
+	StatefulSetExists bool
+	DesiredReplicas   int32
+	// Number of Pods currently run by the StatefulSet
+	CurrentReplicas int32
+	ReadyReplicas   int32
+	// The StatefulSet has not finished rolling out its latest revision
+	RolloutInProgress bool
+	// At least one Pod runs a RabbitMQ image different from the desired image
+	ImageChanging bool
+	// The nodes are being moved to PersistentVolumes of a new StorageClass
+	StorageMigrationInProgress bool
+	// ClusterAvailable is True, and no network partition or resource alarm is reported
+	Healthy bool
+	// The cluster has reached the Running phase before
+	HasBeenRunning bool
+}
+
+func NextPhase(current ClusterPhase, observation PhaseObservation) ClusterPhase {
+	switch {
+	case current == PhaseDeleting || observation.Deleting:
+		return PhaseDeleting
+	case observation.ReconcileFailed:
+		return PhaseFailed
+	case observation.StorageMigrationInProgress && observation.HasBeenRunning:
+		return PhaseMigratingStorage
+	case !observation.StatefulSetExists:
+		return PhaseCreating
+	}
+
+	allReplicasReady := observation.ReadyReplicas >= observation.DesiredReplicas
+
+	if !observation.HasBeenRunning {
+		if allReplicasReady && observation.Healthy {
+			return PhaseRunning
+		}
+		return PhaseInitializing
+	}
+
+	switch {
+	case observation.CurrentReplicas > observation.DesiredReplicas:
+		return PhaseScalingDown
+	case observation.RolloutInProgress && observation.ImageChanging:
+		return PhaseUpgrading
+	case observation.RolloutInProgress:
+		return PhaseUpdating
+	case !allReplicasReady || !observation.Healthy:
+		return PhaseDegraded
+	}
+	return PhaseRunning
+}
@@ -0,0 +1,127 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/rabbitmq/cluster-operator/internal/status"
+)
+
+var _ = Describe("NextPhase", func() {
+	var (
+		allPhases = []ClusterPhase{
+			"",
+			PhaseCreating,
+			PhaseInitializing,
+			PhaseRunning,
+			PhaseUpdating,
+			PhaseUpgrading,
+			PhaseScalingDown,
+			PhaseMigratingStorage,
+			PhaseDegraded,
+			PhaseDeleting,
+			PhaseFailed,
+		}
+
+		// a healthy three node cluster with a completed rollout
+		healthy = func() PhaseObservation {
+			return PhaseObservation{
+				StatefulSetExists: true,
+				DesiredReplicas:   3,
+				CurrentReplicas:   3,
+				ReadyReplicas:     3,
+				Healthy:           true,
+			}
+		}
+		with = func(mutate func(*PhaseObservation)) PhaseObservation {
+			observation := healthy()
+			mutate(&observation)
+			return observation
+		}
+		// the same observations, for a cluster that has been Running before
+		running = func(mutate func(*PhaseObservation)) PhaseObservation {
+			observation := with(mutate)
+			observation.HasBeenRunning = true
+			return observation
+		}
+
+		entries = func(from []ClusterPhase, description string, observation PhaseObservation, expected ClusterPhase) []table.TableEntry {
+			var result []table.TableEntry
+			for _, phase := range from {
+				result = append(result, table.Entry(fmt.Sprintf("%q -> %s when %s", phase, expected, description), phase, observation, expected))
+			}
+			return result
+		}
+		concat = func(lists ...[]table.TableEntry) []table.TableEntry {
+			var result []table.TableEntry
+			for _, list := range lists {
+				result = append(result, list...)
+			}
+			return result
+		}
+		withoutDeleting = func(phases []ClusterPhase) []ClusterPhase {
+			var result []ClusterPhase
+			for _, phase := range phases {
+				if phase != PhaseDeleting {
+					result = append(result, phase)
+				}
+			}
+			return result
+		}
+	)
+
+	table.DescribeTable("transitions",
+		func(current ClusterPhase, observation PhaseObservation, expected ClusterPhase) {
+			Expect(NextPhase(current, observation)).To(Equal(expected))
+		},
+		concat(
+			entries(allPhases, "the cluster is marked for deletion",
+				with(func(o *PhaseObservation) { o.Deleting = true }), PhaseDeleting),
+			entries([]ClusterPhase{PhaseDeleting}, "the cluster is otherwise healthy",
+				healthy(), PhaseDeleting),
+			entries(withoutDeleting(allPhases), "the reconciliation failed",
+				with(func(o *PhaseObservation) { o.ReconcileFailed = true }), PhaseFailed),
+			entries(withoutDeleting(allPhases), "the StatefulSet does not exist",
+				PhaseObservation{DesiredReplicas: 3, Healthy: true}, PhaseCreating),
+
+			entries(withoutDeleting(allPhases), "all replicas are ready and the cluster is healthy",
+				healthy(), PhaseRunning),
+			entries(withoutDeleting(allPhases), "not all replicas are ready",
+				with(func(o *PhaseObservation) { o.ReadyReplicas = 1 }), PhaseInitializing),
+			entries(withoutDeleting(allPhases), "the cluster is not healthy",
+				with(func(o *PhaseObservation) { o.Healthy = false }), PhaseInitializing),
+			entries(withoutDeleting(allPhases), "the initial rollout is in progress",
+				with(func(o *PhaseObservation) { o.RolloutInProgress = true; o.ReadyReplicas = 2 }), PhaseInitializing),
+
+			entries(withoutDeleting(allPhases), "the cluster has been Running and the StatefulSet runs more Pods than desired",
+				running(func(o *PhaseObservation) { o.CurrentReplicas = 5; o.RolloutInProgress = true }), PhaseScalingDown),
+			entries(withoutDeleting(allPhases), "the cluster has been Running and the nodes are being moved to a new StorageClass",
+				running(func(o *PhaseObservation) { o.StorageMigrationInProgress = true; o.ReadyReplicas = 2 }), PhaseMigratingStorage),
+			entries(withoutDeleting(allPhases), "the cluster has been Running and the StatefulSet is recreated to move the nodes to a new StorageClass",
+				running(func(o *PhaseObservation) { o.StorageMigrationInProgress = true; o.StatefulSetExists = false }), PhaseMigratingStorage),
+			entries(withoutDeleting(allPhases), "the cluster has not been Running and the nodes are being moved to a new StorageClass",
+				with(func(o *PhaseObservation) { o.StorageMigrationInProgress = true; o.ReadyReplicas = 2 }), PhaseInitializing),
+			entries(withoutDeleting(allPhases), "the cluster has been Running and a rollout changes the image",
+				running(func(o *PhaseObservation) { o.RolloutInProgress = true; o.ImageChanging = true; o.ReadyReplicas = 2 }), PhaseUpgrading),
+			entries(withoutDeleting(allPhases), "the cluster has been Running and a rollout is in progress",
+				running(func(o *PhaseObservation) { o.RolloutInProgress = true; o.ReadyReplicas = 2 }), PhaseUpdating),
+			entries(withoutDeleting(allPhases), "the cluster has been Running and not all replicas are ready",
+				running(func(o *PhaseObservation) { o.ReadyReplicas = 2 }), PhaseDegraded),
+			entries(withoutDeleting(allPhases), "the cluster has been Running and is not healthy",
+				running(func(o *PhaseObservation) { o.Healthy = false }), PhaseDegraded),
+			entries(withoutDeleting(allPhases), "the cluster has been Running and all replicas are ready and the cluster is healthy",
+				running(func(o *PhaseObservation) {}), PhaseRunning),
+		)...,
+	)
+})
@@ -0,0 +1,79 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// conditions the Ready condition is aggregated from, with the reason used when they are not True
+var readyDependencies = []struct {
+	conditionType RabbitmqClusterConditionType
+	reason        string
+}{
+	{AllReplicasReady, "NotAllReplicasReady"},
+	{ClusterAvailable, "ClusterNotAvailable"},
+	{ReconcileSuccess, "ReconcileNotSuccessful"},
+}
+
+func ReadyCondition(conditions []RabbitmqClusterCondition,
+	oldCondition *RabbitmqClusterCondition) RabbitmqClusterCondition {
+
+	var messages []string
+	condition := newRabbitmqClusterCondition(Ready)
+	if oldCondition != nil {
+		condition.LastTransitionTime = oldCondition.LastTransitionTime
+	}
+
+	condition.Status = corev1.ConditionTrue
+	condition.Reason = "ClusterReady"
+
+	for _, dependency := range readyDependencies {
+		dependencyStatus := corev1.ConditionUnknown
+		dependencyMessage := "condition not reported yet"
+		for _, c := range conditions {
+			if c.Type == dependency.conditionType {
+				dependencyStatus = c.Status
+				dependencyMessage = c.Message
+			}
+		}
+
+		if dependencyStatus == corev1.ConditionTrue {
+			continue
+		}
+
+		// False takes precedence over Unknown
+		if condition.Status == corev1.ConditionTrue ||
+			(condition.Status == corev1.ConditionUnknown && dependencyStatus == corev1.ConditionFalse) {
+			condition.Status = dependencyStatus
+			condition.Reason = dependency.reason
+		}
+
+		message := fmt.Sprintf("%s is %s", dependency.conditionType, dependencyStatus)
+		if dependencyMessage != "" {
+			message = fmt.Sprintf("%s: %s", message, dependencyMessage)
+		}
+		messages = append(messages, message)
+	}
+	condition.Message = strings.Join(messages, ". ")
+
+	if oldCondition == nil || oldCondition.Status != condition.Status {
+		condition.LastTransitionTime = metav1.Time{
+			Time: time.Now(),
+		}
+	}
+
+	return condition
+}
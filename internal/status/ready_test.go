@@ -0,0 +1,155 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package status_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqstatus "github.com/rabbitmq/cluster-operator/internal/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Ready", func() {
+	var (
+		conditions        []rabbitmqstatus.RabbitmqClusterCondition
+		existingCondition *rabbitmqstatus.RabbitmqClusterCondition
+	)
+
+	BeforeEach(func() {
+		conditions = []rabbitmqstatus.RabbitmqClusterCondition{
+			{Type: rabbitmqstatus.AllReplicasReady, Status: corev1.ConditionTrue},
+			{Type: rabbitmqstatus.ClusterAvailable, Status: corev1.ConditionTrue},
+			{Type: rabbitmqstatus.NoWarnings, Status: corev1.ConditionFalse, Message: "ignored"},
+			{Type: rabbitmqstatus.ReconcileSuccess, Status: corev1.ConditionTrue},
+		}
+		existingCondition = nil
+	})
+
+	Context("condition status and reason", func() {
+		When("replicas are ready, the cluster is available and reconciled", func() {
+			It("returns a condition with state true", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.Type).To(Equal(rabbitmqstatus.Ready))
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("ClusterReady"))
+				Expect(condition.Message).To(BeEmpty())
+			})
+		})
+
+		When("not all replicas are ready", func() {
+			BeforeEach(func() {
+				conditions[0].Status = corev1.ConditionFalse
+				conditions[0].Message = "1/3 Pods ready"
+			})
+
+			It("returns a condition with state false", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("NotAllReplicasReady"))
+				Expect(condition.Message).To(Equal("AllReplicasReady is False: 1/3 Pods ready"))
+			})
+		})
+
+		When("the cluster is not available", func() {
+			BeforeEach(func() {
+				conditions[1].Status = corev1.ConditionFalse
+			})
+
+			It("returns a condition with state false", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("ClusterNotAvailable"))
+			})
+		})
+
+		When("the reconciliation failed", func() {
+			BeforeEach(func() {
+				conditions[3].Status = corev1.ConditionFalse
+				conditions[3].Message = "some error"
+			})
+
+			It("returns a condition with state false", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("ReconcileNotSuccessful"))
+				Expect(condition.Message).To(Equal("ReconcileSuccess is False: some error"))
+			})
+		})
+
+		When("a dependency is unknown and another is false", func() {
+			BeforeEach(func() {
+				conditions[0].Status = corev1.ConditionUnknown
+				conditions[3].Status = corev1.ConditionFalse
+			})
+
+			It("reports the false dependency", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("ReconcileNotSuccessful"))
+				Expect(condition.Message).To(Equal("AllReplicasReady is Unknown. ReconcileSuccess is False"))
+			})
+		})
+
+		When("a dependency has not been reported", func() {
+			BeforeEach(func() {
+				conditions = conditions[:2]
+			})
+
+			It("returns a condition with state unknown", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.Status).To(Equal(corev1.ConditionUnknown))
+				Expect(condition.Reason).To(Equal("ReconcileNotSuccessful"))
+			})
+		})
+	})
+
+	Context("condition transitions", func() {
+		var previousConditionTime time.Time
+
+		BeforeEach(func() {
+			previousConditionTime = time.Date(2020, 2, 2, 8, 0, 0, 0, time.UTC)
+			existingCondition = &rabbitmqstatus.RabbitmqClusterCondition{
+				Status: corev1.ConditionTrue,
+				LastTransitionTime: metav1.Time{
+					Time: previousConditionTime,
+				},
+			}
+		})
+
+		When("remains true", func() {
+			It("keeps the transition timestamp", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.LastTransitionTime.Time).To(Equal(previousConditionTime))
+			})
+		})
+
+		When("transitions to false", func() {
+			BeforeEach(func() {
+				conditions[1].Status = corev1.ConditionFalse
+			})
+
+			It("updates the transition timestamp", func() {
+				condition := rabbitmqstatus.ReadyCondition(conditions, existingCondition)
+
+				Expect(condition.LastTransitionTime.Time.After(previousConditionTime)).To(BeTrue())
+			})
+		})
+	})
+})
@@ -19,19 +19,26 @@ import (
 )
 
 const (
-	AllReplicasReady RabbitmqClusterConditionType = "AllReplicasReady"
-	ClusterAvailable RabbitmqClusterConditionType = "ClusterAvailable"
-	NoWarnings       RabbitmqClusterConditionType = "NoWarnings"
-	ReconcileSuccess RabbitmqClusterConditionType = "ReconcileSuccess"
+	AllReplicasReady    RabbitmqClusterConditionType = "AllReplicasReady"
+	ClusterAvailable    RabbitmqClusterConditionType = "ClusterAvailable"
+	NoWarnings          RabbitmqClusterConditionType = "NoWarnings"
+	ReconcileSuccess    RabbitmqClusterConditionType = "ReconcileSuccess"
+	NoNetworkPartitions RabbitmqClusterConditionType = "NoNetworkPartitions"
+	NoResourceAlarms    RabbitmqClusterConditionType = "NoResourceAlarms"
+	Ready               RabbitmqClusterConditionType = "Ready"
 )
 
 type RabbitmqClusterConditionType string
 
+// RabbitmqClusterCondition has the same fields as metav1.Condition, so that
+// generic tooling (kubectl wait, kstatus, Argo CD, Flux) can interpret it.
 type RabbitmqClusterCondition struct {
 	// Type indicates the scope of RabbitmqCluster status addressed by the condition.
 	Type RabbitmqClusterConditionType `json:"type"`
 	// True, False, or Unknown
 	Status corev1.ConditionStatus `json:"status"`
+	// The .metadata.generation of the RabbitmqCluster the condition was set based upon.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// The last time this Condition type changed.
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 	// One word, camel-case reason for current status of the condition.
@@ -59,3 +66,15 @@ func (condition *RabbitmqClusterCondition) UpdateReason(reason string, messages
 	condition.Reason = reason
 	condition.Message = strings.Join(messages, ". ")
 }
+
+// KeepTransitionTimes restores the LastTransitionTime of the conditions whose status is the same as in oldConditions,
+// so that a condition computed again, and then overridden, within a reconciliation does not appear to transition
+func KeepTransitionTimes(conditions, oldConditions []RabbitmqClusterCondition) {
+	for i := range conditions {
+		for _, oldCondition := range oldConditions {
+			if oldCondition.Type == conditions[i].Type && oldCondition.Status == conditions[i].Status {
+				conditions[i].LastTransitionTime = oldCondition.LastTransitionTime
+			}
+		}
+	}
+}
@@ -50,4 +50,23 @@ var _ = Describe("Status", func() {
 		})
 	})
 
+	Context("KeepTransitionTimes", func() {
+		It("only keeps the transition time of the conditions whose status did not change", func() {
+			oldTime := metav1.Unix(1, 1)
+			newTime := metav1.Unix(2, 2)
+			oldConditions := []RabbitmqClusterCondition{
+				{Type: NoWarnings, Status: corev1.ConditionFalse, LastTransitionTime: oldTime},
+				{Type: AllReplicasReady, Status: corev1.ConditionFalse, LastTransitionTime: oldTime},
+			}
+			conditions := []RabbitmqClusterCondition{
+				{Type: NoWarnings, Status: corev1.ConditionFalse, LastTransitionTime: newTime},
+				{Type: AllReplicasReady, Status: corev1.ConditionTrue, LastTransitionTime: newTime},
+			}
+
+			KeepTransitionTimes(conditions, oldConditions)
+			Expect(conditions[0].LastTransitionTime).To(Equal(oldTime))
+			Expect(conditions[1].LastTransitionTime).To(Equal(newTime))
+		})
+	})
+
 })
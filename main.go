@@ -9,6 +9,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -21,6 +22,9 @@ import (
 
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	"github.com/rabbitmq/cluster-operator/controllers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	defaultscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -29,7 +33,11 @@ import (
 	// +kubebuilder:scaffold:imports
 )
 
-const controllerName = "rabbitmqcluster-controller"
+const (
+	controllerName         = "rabbitmqcluster-controller"
+	snapshotControllerName = "rabbitmqsnapshot-controller"
+	operatorContainerName  = "operator"
+)
 
 var (
 	scheme = runtime.NewScheme()
@@ -100,20 +108,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientset := kubernetes.NewForConfigOrDie(clusterConfig)
+
+	operatorPod, err := getOperatorPod(clientset, operatorNamespace)
+	if err != nil {
+		log.Error(err, "unable to get the operator Pod; NetworkPolicies of RabbitmqClusters will not allow the operator to reach the management API")
+	}
+	operatorPodLabels := getOperatorPodLabels(operatorPod)
+
+	setupContainerImage, err := getSetupContainerImage(operatorPod)
+	if err != nil {
+		log.Error(err, "unable to find the image of the operator; set SETUP_CONTAINER_IMAGE to the image of the operator, or allow the operator to get its own Pod")
+		os.Exit(1)
+	}
+	log.Info("RabbitMQ Pods use setup-container image", "image", setupContainerImage)
+
 	err = (&controllers.RabbitmqClusterReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName(controllerName),
+		Scheme:              mgr.GetScheme(),
+		Recorder:            mgr.GetEventRecorderFor(controllerName),
+		Namespace:           operatorNamespace,
+		ClusterConfig:       clusterConfig,
+		Clientset:           clientset,
+		SetupContainerImage: setupContainerImage,
+		OperatorPodLabels:   operatorPodLabels,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		log.Error(err, "unable to create controller", controllerName)
+		os.Exit(1)
+	}
+	log.Info("started controller")
+
+	err = (&controllers.RabbitmqSnapshotReconciler{
 		Client:        mgr.GetClient(),
-		Log:           ctrl.Log.WithName(controllerName),
+		Log:           ctrl.Log.WithName(snapshotControllerName),
 		Scheme:        mgr.GetScheme(),
-		Recorder:      mgr.GetEventRecorderFor(controllerName),
-		Namespace:     operatorNamespace,
+		Recorder:      mgr.GetEventRecorderFor(snapshotControllerName),
 		ClusterConfig: clusterConfig,
-		Clientset:     kubernetes.NewForConfigOrDie(clusterConfig),
+		Clientset:     clientset,
 	}).SetupWithManager(mgr)
 	if err != nil {
-		log.Error(err, "unable to create controller", controllerName)
+		log.Error(err, "unable to create controller", snapshotControllerName)
 		os.Exit(1)
 	}
-	log.Info("started controller")
+	log.Info("started controller", "controller", snapshotControllerName)
 	// +kubebuilder:scaffold:builder
 
 	log.Info("starting manager")
@@ -134,3 +173,49 @@ func getEnvInDuration(envName string) time.Duration {
 	}
 	return time.Duration(durationInt) * time.Second
 }
+
+// getOperatorPod returns the Pod the operator runs in
+func getOperatorPod(clientset kubernetes.Interface, operatorNamespace string) (*corev1.Pod, error) {
+	// the hostname of a Pod is its name
+	podName, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	pod, err := clientset.CoreV1().Pods(operatorNamespace).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the operator Pod %s: %w", podName, err)
+	}
+	return pod, nil
+}
+
+// getOperatorPodLabels returns the labels of the operator Pod, except the ones that change with each rollout of the operator
+func getOperatorPodLabels(pod *corev1.Pod) map[string]string {
+	if pod == nil {
+		return nil
+	}
+	labels := map[string]string{}
+	for key, value := range pod.Labels {
+		if key != appsv1.DefaultDeploymentUniqueLabelKey {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// getSetupContainerImage returns SETUP_CONTAINER_IMAGE, or the image of the operator container,
+// which ships the setup-container binary of the RabbitMQ Pods
+func getSetupContainerImage(pod *corev1.Pod) (string, error) {
+	if image := os.Getenv("SETUP_CONTAINER_IMAGE"); image != "" {
+		return image, nil
+	}
+
+	if pod == nil {
+		return "", fmt.Errorf("the operator Pod is unknown")
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == operatorContainerName {
+			return container.Image, nil
+		}
+	}
+	return "", fmt.Errorf("operator Pod %s has no container named %s", pod.Name, operatorContainerName)
+}